@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go-devops-tool/secureops"
+)
+
+// Échecs d'écriture du journal d'audit depuis le lancement. L'action
+// concernée a été effectuée : c'est un avertissement, qui donne le code de
+// sortie exitAudit en mode non interactif.
+var (
+	auditWarningsMu sync.Mutex
+	auditWarnings   []string
+)
+
+// audit journalise une action dans OutDir/audit.log
+func audit(cfg Config, action, target string, params secureops.Params, err error) {
+	if lerr := secureops.LogAction(cfg.OutDir, action, target, params, err); lerr != nil {
+		warnAudit(lerr)
+	}
+}
+
+// warnAudit signale un échec de journalisation sur la sortie d'erreur et le retient
+func warnAudit(err error) {
+	auditWarningsMu.Lock()
+	auditWarnings = append(auditWarnings, err.Error())
+	auditWarningsMu.Unlock()
+	fmt.Fprintln(os.Stderr, "Avertissement :", err)
+}
+
+// auditFailures retourne les échecs de journalisation signalés
+func auditFailures() []string {
+	auditWarningsMu.Lock()
+	defer auditWarningsMu.Unlock()
+	return append([]string(nil), auditWarnings...)
+}
+
+// actionError sépare l'échec d'une action de celui de sa journalisation : un
+// *secureops.AuditError (action effectuée, journal non écrit) devient un
+// avertissement et nil est retourné
+func actionError(err error) error {
+	var auditErr *secureops.AuditError
+	if errors.As(err, &auditErr) {
+		warnAudit(auditErr)
+		return nil
+	}
+	return err
+}
+
+// Chemin absolu d'un fichier pour le journal d'audit
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,1139 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-devops-tool/fileops"
+	"go-devops-tool/procops"
+	"go-devops-tool/secureops"
+	"go-devops-tool/sysops"
+)
+
+// Durée de mesure de l'utilisation CPU de la vue système
+const cpuSample = 500 * time.Millisecond
+
+// Codes de sortie des sous-commandes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+	exitAudit = 3 // action effectuée, mais journal d'audit non écrit
+)
+
+// Aide affichée pour le mode non interactif
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage : tool [--config FICHIER] [--output json|text] [commande] [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Sans commande, le menu interactif est lancé.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commandes :")
+	fmt.Fprintln(os.Stderr, "  file info PATH                    Taille et nombre de lignes")
+	fmt.Fprintln(os.Stderr, "  file stats PATH                   Statistiques mots")
+	fmt.Fprintln(os.Stderr, "  file head PATH [-n N] [-o FICHIER] [--wait 10s]")
+	fmt.Fprintln(os.Stderr, "  file tail PATH [-n N] [-o FICHIER] [--wait 10s]")
+	fmt.Fprintln(os.Stderr, "  file count PATH MOT               Lignes contenant le mot-clé")
+	fmt.Fprintln(os.Stderr, "  file filter PATH MOT [--exclude] [-o FICHIER] [--wait 10s]")
+	fmt.Fprintln(os.Stderr, "  batch [DIR] [--wait 10s]          Rapport, index et fusion des .txt")
+	fmt.Fprintln(os.Stderr, "                                    Les sorties verrouillées sont refusées (--wait : attendre)")
+	fmt.Fprintln(os.Stderr, "  wiki ARTICLE                      Statistiques d'un article Wikipédia")
+	fmt.Fprintln(os.Stderr, "  proc list [--top N] [--sort CLÉ] [--order asc|desc] [--cgroup CHEMIN]")
+	fmt.Fprintln(os.Stderr, "  proc top [--interval 2s] [--top N] [--sort CLÉ]   Vue temps réel (q pour quitter)")
+	fmt.Fprintln(os.Stderr, "  proc find MOT [--cgroup CHEMIN]   Rechercher un processus")
+	fmt.Fprintln(os.Stderr, "  proc cgroups [CHEMIN]             Processus groupés par cgroup / conteneur (Linux)")
+	fmt.Fprintln(os.Stderr, "  proc tree [PID|MOT]               Arbre des processus (complet ou sous-arbre)")
+	fmt.Fprintln(os.Stderr, "  proc ports                        Sockets en écoute et processus associés")
+	fmt.Fprintln(os.Stderr, "  proc port PORT                    Quel processus écoute sur ce port")
+	fmt.Fprintln(os.Stderr, "  proc fds PID                      Descripteurs ouverts d'un processus")
+	fmt.Fprintln(os.Stderr, "  proc who PATH                     Processus ayant ouvert un fichier")
+	fmt.Fprintln(os.Stderr, "  proc services                     Services définis dans la config")
+	fmt.Fprintln(os.Stderr, "  proc supervise [SERVICE...]       Lance et supervise les services (Ctrl+C pour arrêter)")
+	fmt.Fprintln(os.Stderr, "  proc snapshot                     Enregistrer un relevé des processus dans OutDir")
+	fmt.Fprintln(os.Stderr, "  proc diff AVANT [APRÈS] [--top N] Comparer deux relevés (APRÈS absent = maintenant)")
+	fmt.Fprintln(os.Stderr, "  proc zombies [--min-age 1h]       Zombies par parent et orphelins de longue durée")
+	fmt.Fprintln(os.Stderr, "  proc monitor [--interval 2s]      Alertes de seuils CPU/RSS/fds/durée (Ctrl+C pour arrêter)")
+	fmt.Fprintln(os.Stderr, "  proc run [--timeout 30s] -- CMD [ARGS...]  Exécuter avec délai max et mesurer le coût")
+	fmt.Fprintln(os.Stderr, "  proc kill PID [--signal SIG] [--grace 5s]  Signal, ou TERM puis KILL")
+	fmt.Fprintln(os.Stderr, "  proc pkill MOTIF [--regex] [--cmdline] [--signal SIG] [--confirm N]")
+	fmt.Fprintln(os.Stderr, "                                    Simulation, puis kill si N = nombre visé")
+	fmt.Fprintln(os.Stderr, "  sys [--sample 500ms]              Charge, mémoire, uptime, CPU et disques")
+	fmt.Fprintln(os.Stderr, "  secure lock PATH [--reason R] [--ttl 1h] [--hold]  Verrouiller un fichier")
+	fmt.Fprintln(os.Stderr, "                                    --hold : verrou levé à Ctrl+C, périmé si l'outil disparaît")
+	fmt.Fprintln(os.Stderr, "  secure unlock PATH                Déverrouiller un fichier")
+	fmt.Fprintln(os.Stderr, "  secure locks                      Verrous posés dans OutDir (propriétaire, état)")
+	fmt.Fprintln(os.Stderr, "  secure break PATH                 Supprimer un verrou périmé (TTL, propriétaire disparu)")
+	fmt.Fprintln(os.Stderr, "  secure readonly PATH [--off]      Basculer la lecture seule")
+	fmt.Fprintln(os.Stderr, "  secure flock PATH [--shared] [--wait 10s|--block] -- CMD [ARGS...]")
+	fmt.Fprintln(os.Stderr, "                                    Exécuter une commande en détenant un flock sur PATH")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Options globales :")
+	flag.PrintDefaults()
+}
+
+// cli porte le contexte d'exécution d'une sous-commande
+type cli struct {
+	config  Config
+	format  string // outputText ou outputJSON
+	command string // nom stable de l'opération (ex: file.info)
+}
+
+// Erreur d'utilisation (arguments manquants ou invalides)
+func (c *cli) usageError(format string, a ...any) int {
+	msg := fmt.Sprintf(format, a...)
+	if c.format == outputJSON {
+		writeJSON(envelope{Command: c.command, Error: msg})
+		return exitUsage
+	}
+	fmt.Fprintln(os.Stderr, "Erreur :", msg)
+	fmt.Fprintln(os.Stderr, "Voir 'tool help' pour l'aide.")
+	return exitUsage
+}
+
+// Erreur d'exécution d'une opération
+func (c *cli) fail(context string, err error) int {
+	if c.format == outputJSON {
+		writeJSON(envelope{Command: c.command, Error: context + " : " + err.Error()})
+		return exitError
+	}
+	fmt.Fprintln(os.Stderr, context, ":", err)
+	return exitError
+}
+
+// Succès : document JSON ou affichage texte selon le format choisi
+func (c *cli) done(data any, text func()) int {
+	if c.format == outputJSON {
+		writeJSON(envelope{Command: c.command, OK: true, Data: data})
+	} else {
+		text()
+	}
+	if len(auditFailures()) > 0 {
+		return exitAudit
+	}
+	return exitOK
+}
+
+// parseArgs analyse les options d'une sous-commande en acceptant qu'elles
+// apparaissent après les arguments positionnels (ex: file head PATH -n 20)
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// splitCommand sépare les arguments de l'outil de la commande à lancer,
+// placée après -- (proc run, secure flock)
+func splitCommand(args []string) (own, command []string) {
+	for i, a := range args {
+		if a == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// runCommand exécute une sous-commande et retourne le code de sortie
+func runCommand(config Config, format string, args []string) int {
+	c := &cli{config: config, format: format, command: args[0]}
+	if format != outputText && format != outputJSON {
+		c.format = outputText
+		return c.usageError("format de sortie inconnu '%s' (json ou text)", format)
+	}
+
+	// Nom de l'opération : commande + sous-commande (ex: proc.list)
+	if len(args) > 1 && args[0] != "batch" && args[0] != "wiki" && args[0] != "sys" {
+		c.command += "." + args[1]
+	}
+
+	switch args[0] {
+	case "file":
+		return c.runFile(args[1:])
+	case "batch":
+		return c.runBatch(args[1:])
+	case "wiki":
+		return c.runWiki(args[1:])
+	case "proc":
+		return c.runProc(args[1:])
+	case "secure":
+		return c.runSecure(args[1:])
+	case "sys":
+		return c.runSys(args[1:])
+	case "help", "-h", "--help":
+		usage()
+		return exitOK
+	default:
+		return c.usageError("commande inconnue '%s'", args[0])
+	}
+}
+
+// Sous-commandes FileOps
+func (c *cli) runFile(args []string) int {
+	if len(args) == 0 {
+		return c.usageError("sous-commande file manquante (info, stats, head, tail, count, filter)")
+	}
+
+	fs := flag.NewFlagSet("file "+args[0], flag.ContinueOnError)
+	n := fs.Int("n", 10, "Nombre de lignes")
+	out := fs.String("o", "", "Fichier de sortie")
+	exclude := fs.Bool("exclude", false, "Garder les lignes ne contenant pas le mot-clé")
+	wait := fs.Duration("wait", lockWait(c.config), "Attente d'un fichier verrouillé, 0 = refus immédiat")
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+	fileops.UseLocks(c.config.OutDir, *wait)
+	if len(pos) == 0 {
+		return c.usageError("chemin du fichier manquant")
+	}
+	path := pos[0]
+
+	switch args[0] {
+	case "info":
+		if err := fileops.CheckFile(path); err != nil {
+			return c.fail("Fichier invalide", err)
+		}
+		size, lines, err := fileops.FileInfo(path)
+		if err != nil {
+			return c.fail("Erreur lecture fichier", err)
+		}
+		return c.done(fileInfoResult{Path: path, SizeBytes: size, Lines: lines}, func() {
+			fmt.Println("Taille :", size, "octets")
+			fmt.Println("Nombre de lignes :", lines)
+		})
+
+	case "stats":
+		words, avg, err := fileops.WordStats(path)
+		if err != nil {
+			return c.fail("Erreur lecture fichier", err)
+		}
+		return c.done(wordStatsResult{Path: path, Words: words, AvgWordLength: avg}, func() {
+			fmt.Println("Nombre de mots :", words)
+			fmt.Printf("Longueur moyenne des mots : %.2f\n", avg)
+		})
+
+	case "head", "tail":
+		if *n < 0 {
+			return c.usageError("nombre de lignes invalide : %d", *n)
+		}
+		outFile := *out
+		if outFile == "" {
+			outFile = filepath.Join(c.config.OutDir, args[0]+".txt")
+		}
+		op := fileops.Head
+		if args[0] == "tail" {
+			op = fileops.Tail
+		}
+		if err := op(path, *n, outFile); err != nil {
+			return c.fail("Erreur", err)
+		}
+		return c.done(extractResult{Path: path, Lines: *n, Output: outFile}, func() {
+			fmt.Println("Sauvegardé dans :", outFile)
+		})
+
+	case "count":
+		if len(pos) < 2 {
+			return c.usageError("mot-clé manquant")
+		}
+		count, err := fileops.CountLinesWithKeyword(path, pos[1])
+		if err != nil {
+			return c.fail("Erreur", err)
+		}
+		return c.done(countResult{Path: path, Keyword: pos[1], Count: count}, func() {
+			fmt.Printf("Nombre de lignes contenant '%s' : %d\n", pos[1], count)
+		})
+
+	case "filter":
+		if len(pos) < 2 {
+			return c.usageError("mot-clé manquant")
+		}
+		outFile := *out
+		if outFile == "" {
+			outFile = filepath.Join(c.config.OutDir, "filtered.txt")
+		}
+		if err := fileops.FilterLines(path, pos[1], outFile, !*exclude); err != nil {
+			return c.fail("Erreur", err)
+		}
+		return c.done(filterResult{Path: path, Keyword: pos[1], Include: !*exclude, Output: outFile}, func() {
+			fmt.Println("Filtre sauvegardé dans :", outFile)
+		})
+
+	default:
+		return c.usageError("sous-commande file inconnue '%s'", args[0])
+	}
+}
+
+// Sous-commande Batch
+func (c *cli) runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	wait := fs.Duration("wait", lockWait(c.config), "Attente d'un fichier verrouillé, 0 = refus immédiat")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+	fileops.UseLocks(c.config.OutDir, *wait)
+	dir := c.config.BaseDir
+	if len(pos) > 0 {
+		dir = pos[0]
+	}
+
+	stats, err := fileops.BatchStats(dir)
+	if err != nil {
+		return c.fail("Erreur Rapport", err)
+	}
+	if stats == nil {
+		stats = []fileops.FileStats{}
+	}
+
+	result := batchResult{
+		Dir:    dir,
+		Files:  stats,
+		Report: filepath.Join(c.config.OutDir, "report.txt"),
+		Index:  filepath.Join(c.config.OutDir, "index.txt"),
+		Merged: filepath.Join(c.config.OutDir, "merged.txt"),
+	}
+	if err := fileops.BatchWordStats(dir, c.config.OutDir); err != nil {
+		return c.fail("Erreur Rapport", err)
+	}
+	if err := fileops.BatchIndex(dir, result.Index); err != nil {
+		return c.fail("Erreur Index", err)
+	}
+	if err := fileops.BatchMerge(dir, result.Merged); err != nil {
+		return c.fail("Erreur Fusion", err)
+	}
+
+	return c.done(result, func() {
+		fmt.Println("Rapport généré dans :", result.Report)
+		fmt.Println("Index généré dans :", result.Index)
+		fmt.Println("Fusion terminée dans :", result.Merged)
+	})
+}
+
+// Sous-commande WebOps
+func (c *cli) runWiki(args []string) int {
+	if len(args) == 0 || args[0] == "" {
+		return c.usageError("nom de l'article manquant")
+	}
+	article := args[0]
+
+	text, err := fetchWikiArticle(article)
+	if err != nil {
+		return c.fail("Erreur Wikipédia", err)
+	}
+	totalWords, avgLength := textWordStats(text)
+
+	outFile := filepath.Join(c.config.OutDir, "wiki_"+article+".txt")
+	if err := os.WriteFile(outFile, []byte(text), 0644); err != nil {
+		return c.fail("Erreur sauvegarde", err)
+	}
+	return c.done(wikiResult{Article: article, Words: totalWords, AvgWordLength: avgLength, Output: outFile}, func() {
+		fmt.Println("Stats de l'article :")
+		fmt.Println("Nombre de mots :", totalWords)
+		fmt.Printf("Longueur moyenne des mots : %.2f\n", avgLength)
+		fmt.Println("Article sauvegardé dans :", outFile)
+	})
+}
+
+// Sous-commandes ProcOps
+func (c *cli) runProc(args []string) int {
+	if len(args) == 0 {
+		return c.usageError("sous-commande proc manquante (list, find, kill)")
+	}
+
+	defaults, err := processListOptions(c.config)
+	if err != nil {
+		return c.fail("Erreur config", err)
+	}
+
+	args, command := splitCommand(args)
+
+	fs := flag.NewFlagSet("proc "+args[0], flag.ContinueOnError)
+	topN := fs.Int("top", defaults.TopN, "Nombre de processus à afficher (0 = tous)")
+	sortBy := fs.String("sort", string(defaults.SortBy), "Tri : cpu, mem, rss, start, pid, name")
+	order := fs.String("order", defaults.Order, "Ordre : asc ou desc (défaut selon le critère)")
+	interval := fs.Duration("interval", processRefresh(c.config), "Intervalle de rafraîchissement (proc top, proc monitor)")
+	sigName := fs.String("signal", "", "Signal à envoyer (proc kill) ; vide = TERM puis KILL")
+	grace := fs.Duration("grace", 0, "Délai de grâce entre TERM et KILL (proc kill)")
+	isRegex := fs.Bool("regex", false, "Le motif est une expression régulière (proc pkill)")
+	inCmdline := fs.Bool("cmdline", false, "Chercher dans la ligne de commande complète (proc pkill)")
+	confirm := fs.Int("confirm", -1, "Nombre de processus visés, requis pour exécuter (proc pkill)")
+	cgroup := fs.String("cgroup", "", "Ne garder que ce cgroup et ses descendants (proc list, proc find)")
+	minAge := fs.Duration("min-age", orphanMinAge(c.config), "Âge minimal des orphelins signalés (proc zombies)")
+	timeout := fs.Duration("timeout", runTimeout(c.config), "Délai maximal, 0 = aucun (proc run)")
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+	key, err := procops.ParseSortKey(*sortBy)
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+	ord, err := procops.ParseOrder(*order)
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+
+	switch args[0] {
+	case "list":
+		procs, err := listInCgroup(*cgroup, procops.ListOptions{TopN: *topN, SortBy: key, Order: ord})
+		if err != nil {
+			return c.fail("Erreur liste", err)
+		}
+		return c.done(newProcessesResult(procs), func() { printProcesses(procs) })
+
+	case "top":
+		if *interval <= 0 {
+			return c.usageError("intervalle invalide %s", *interval)
+		}
+		opts := procops.ListOptions{TopN: *topN, SortBy: key, Order: ord}
+		if c.format == outputJSON {
+			// Un seul relevé : deux échantillons séparés d'un intervalle
+			sampler := procops.NewSampler()
+			if _, err := sampler.Sample(opts); err != nil {
+				return c.fail("Erreur échantillonnage", err)
+			}
+			time.Sleep(*interval)
+			procs, err := sampler.Sample(opts)
+			if err != nil {
+				return c.fail("Erreur échantillonnage", err)
+			}
+			return c.done(newProcessesResult(procs), nil)
+		}
+		if err := runProcWatch(bufio.NewReader(os.Stdin), opts, *interval); err != nil {
+			return c.fail("Erreur vue temps réel", err)
+		}
+		return exitOK
+
+	case "find":
+		if len(pos) == 0 {
+			return c.usageError("mot-clé manquant")
+		}
+		procs, err := procops.FindProcesses(procops.Filter{Pattern: pos[0], Cgroup: *cgroup})
+		if err != nil {
+			return c.fail("Erreur recherche", err)
+		}
+		procops.SortProcesses(procs, key, ord)
+		return c.done(newProcessesResult(procs), func() { printProcesses(procs) })
+
+	case "tree":
+		query := ""
+		if len(pos) > 0 {
+			query = pos[0]
+		}
+		roots, err := processTree(query)
+		if err != nil {
+			return c.fail("Erreur arbre", err)
+		}
+		return c.done(roots, func() { procops.RenderTree(os.Stdout, roots) })
+
+	case "ports":
+		sockets, err := procops.ListeningSockets()
+		if err != nil {
+			return c.fail("Erreur sockets", err)
+		}
+		return c.done(newSocketsResult(sockets), func() { printSockets(sockets) })
+
+	case "port":
+		if len(pos) == 0 {
+			return c.usageError("port manquant")
+		}
+		port, err := strconv.Atoi(pos[0])
+		if err != nil || port <= 0 || port > 65535 {
+			return c.usageError("port invalide '%s'", pos[0])
+		}
+		sockets, err := procops.PortOwners(port)
+		if err != nil {
+			return c.fail("Erreur sockets", err)
+		}
+		if len(sockets) == 0 {
+			return c.fail("Port libre", fmt.Errorf("aucun processus n'écoute sur le port %d", port))
+		}
+		return c.done(newSocketsResult(sockets), func() { printSockets(sockets) })
+
+	case "fds":
+		if len(pos) == 0 {
+			return c.usageError("PID manquant")
+		}
+		pid, err := strconv.Atoi(pos[0])
+		if err != nil {
+			return c.usageError("PID invalide '%s'", pos[0])
+		}
+		fds, err := procops.OpenFiles(pid)
+		if err != nil {
+			return c.fail("Erreur descripteurs", err)
+		}
+		if fds == nil {
+			fds = []procops.FileDescriptor{}
+		}
+		result := fdsResult{PID: pid, Total: len(fds), Counts: procops.CountByKind(fds), FDs: fds}
+		return c.done(result, func() { printFDs(pid, fds) })
+
+	case "who":
+		if len(pos) == 0 {
+			return c.usageError("chemin du fichier manquant")
+		}
+		holders, err := procops.WhoHasOpen(pos[0])
+		if err != nil {
+			return c.fail("Erreur recherche", err)
+		}
+		if holders == nil {
+			holders = []procops.FileHolder{}
+		}
+		result := holdersResult{Path: pos[0], Count: len(holders), Holders: holders}
+		return c.done(result, func() { printHolders(pos[0], holders) })
+
+	case "services":
+		sup, err := newSupervisor(c.config, nil)
+		if err != nil {
+			return c.fail("Erreur config services", err)
+		}
+		statuses := sup.Status()
+		return c.done(statuses, func() { printServices(statuses) })
+
+	case "supervise":
+		// En JSON, la progression passe sur stderr : stdout ne porte que le bilan
+		progress := io.Writer(os.Stdout)
+		if c.format == outputJSON {
+			progress = os.Stderr
+		}
+		summary, err := runSupervisorForeground(c.config, pos, progress)
+		if err != nil {
+			return c.fail("Erreur supervision", err)
+		}
+		return c.done(summary, func() {})
+
+	case "cgroups":
+		prefix := ""
+		if len(pos) > 0 {
+			prefix = pos[0]
+		}
+		groups, err := procops.CgroupGroups(prefix)
+		if err != nil {
+			return c.fail("Erreur cgroups", err)
+		}
+		return c.done(groups, func() { printCgroups(groups) })
+
+	case "snapshot":
+		snap, err := procops.TakeSnapshot()
+		if err != nil {
+			return c.fail("Erreur relevé", err)
+		}
+		path, err := procops.SaveSnapshot(c.config.OutDir, snap)
+		if err != nil {
+			return c.fail("Erreur sauvegarde", err)
+		}
+		result := snapshotResult{Path: path, TakenAt: snap.TakenAt, Count: len(snap.Processes)}
+		return c.done(result, func() { fmt.Printf("%d processus enregistrés dans %s\n", result.Count, path) })
+
+	case "diff":
+		if len(pos) == 0 {
+			return c.usageError("relevé de référence manquant")
+		}
+		diff, err := diffSnapshots(pos[0], pos[1:], *topN)
+		if err != nil {
+			return c.fail("Erreur comparaison", err)
+		}
+		return c.done(diff, func() { printSnapshotDiff(diff) })
+
+	case "zombies":
+		report, err := procops.DiagnoseZombies(*minAge)
+		if err != nil {
+			return c.fail("Erreur diagnostic", err)
+		}
+		return c.done(report, func() { printZombieReport(report) })
+
+	case "monitor":
+		if *interval <= 0 {
+			return c.usageError("intervalle invalide %s", *interval)
+		}
+		mon, err := newMonitor(c.config, c.format == outputText)
+		if err != nil {
+			return c.fail("Erreur surveillance", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		alerts, err := runMonitor(ctx, c.config, mon, *interval, c.format == outputText)
+		if err != nil {
+			return c.fail("Erreur surveillance", err)
+		}
+		return c.done(alerts, func() { fmt.Printf("%d alerte(s) levée(s).\n", len(alerts)) })
+
+	case "run":
+		command = append(pos, command...)
+		if len(command) == 0 {
+			return c.usageError("commande manquante (proc run [--timeout 30s] -- CMD [ARGS...])")
+		}
+		// En JSON, la sortie de la commande passe sur stderr pour garder stdout lisible
+		stdout := io.Writer(os.Stdout)
+		if c.format == outputJSON {
+			stdout = os.Stderr
+		}
+		result, err := runMeasured(c.config, *timeout, stdout, os.Stderr, command[0], command[1:]...)
+		if err != nil {
+			return c.fail("Erreur exécution", err)
+		}
+		code := c.done(result, func() { printRunResult(result) })
+		if result.ExitCode != 0 {
+			return exitError
+		}
+		return code
+
+	case "kill":
+		if len(pos) == 0 {
+			return c.usageError("PID manquant")
+		}
+		pid, err := strconv.Atoi(pos[0])
+		if err != nil {
+			return c.usageError("PID invalide '%s'", pos[0])
+		}
+		if *sigName != "" {
+			if _, err := procops.ParseSignal(*sigName); err != nil {
+				return c.usageError("%v", err)
+			}
+		}
+		cfg := c.config
+		if *grace > 0 {
+			cfg.KillGraceSec = int((*grace + time.Second - 1) / time.Second)
+		}
+		result, err := killProcess(cfg, pid, *sigName)
+		if err != nil {
+			return c.fail("Erreur lors du kill", err)
+		}
+		return c.done(result, func() { fmt.Println(describeKill(result, *sigName == "")) })
+
+	case "pkill":
+		if len(pos) == 0 {
+			return c.usageError("motif manquant")
+		}
+		if *sigName != "" {
+			if _, err := procops.ParseSignal(*sigName); err != nil {
+				return c.usageError("%v", err)
+			}
+		}
+		filter := procops.Filter{Pattern: pos[0], Regex: *isRegex, Cmdline: *inCmdline}
+		targets, protected, err := matchKillTargets(filter)
+		if err != nil {
+			return c.fail("Erreur recherche", err)
+		}
+		if targets == nil {
+			targets = []procops.ProcessInfo{}
+		}
+		if protected == nil {
+			protected = []procops.ProcessInfo{}
+		}
+		result := pkillResult{Pattern: filter.Pattern, Regex: filter.Regex, Cmdline: filter.Cmdline,
+			DryRun: true, Count: len(targets), Targets: targets, Protected: protected}
+
+		// Sans --confirm égal au nombre de processus visés : simulation seulement
+		if *confirm < 0 {
+			return c.done(result, func() {
+				for _, p := range protected {
+					fmt.Println("Ignoré :", procops.CheckProtected(p))
+				}
+				fmt.Println("Simulation — processus visés :")
+				printProcesses(targets)
+				if len(targets) > 0 {
+					fmt.Printf("Relancer avec --confirm %d pour exécuter.\n", len(targets))
+				}
+			})
+		}
+		if *confirm != len(targets) {
+			return c.fail("Confirmation refusée", fmt.Errorf("--confirm %d ne correspond pas aux %d processus visés", *confirm, len(targets)))
+		}
+
+		cfg := c.config
+		if *grace > 0 {
+			cfg.KillGraceSec = int((*grace + time.Second - 1) / time.Second)
+		}
+		outcomes := killMatching(cfg, targets, *sigName)
+		auditRefused(cfg, protected, *sigName)
+		result.DryRun, result.Outcomes = false, outcomes
+		code := c.done(result, func() { printKillOutcomes(outcomes, protected, *sigName == "") })
+		// Tous les processus visés sont protégés : rien n'a été fait
+		if len(targets) == 0 && len(protected) > 0 {
+			return exitError
+		}
+		for _, o := range outcomes {
+			if o.Error != "" {
+				return exitError
+			}
+		}
+		return code
+
+	default:
+		return c.usageError("sous-commande proc inconnue '%s'", args[0])
+	}
+}
+
+// Sous-commandes SecureOps
+func (c *cli) runSecure(args []string) int {
+	if len(args) == 0 {
+		return c.usageError("sous-commande secure manquante (lock, unlock, locks, break, readonly, flock)")
+	}
+
+	args, command := splitCommand(args)
+	fs := flag.NewFlagSet("secure "+args[0], flag.ContinueOnError)
+	off := fs.Bool("off", false, "Désactiver la lecture seule")
+	shared := fs.Bool("shared", false, "Verrou partagé plutôt qu'exclusif, exclusif sous Windows (secure flock)")
+	wait := fs.Duration("wait", 0, "Attente maximale du verrou, 0 = échec immédiat (secure flock)")
+	block := fs.Bool("block", false, "Attendre le verrou sans limite (secure flock)")
+	reason := fs.String("reason", "", "Raison du verrouillage (secure lock)")
+	ttl := fs.Duration("ttl", 0, "Durée de validité du verrou, 0 = illimitée (secure lock)")
+	hold := fs.Bool("hold", false, "Détenir le verrou jusqu'à Ctrl+C ; périmé si l'outil disparaît (secure lock)")
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+
+	// Seule la liste des verrous ne porte pas sur un fichier
+	if args[0] == "locks" {
+		locks, err := secureops.ListLocks(c.config.OutDir)
+		if err != nil {
+			return c.fail("Erreur liste des verrous", err)
+		}
+		result := newLocksResult(locks)
+		return c.done(result, func() { printLocks(result.Locks) })
+	}
+	if len(pos) == 0 {
+		return c.usageError("chemin du fichier manquant")
+	}
+	path := pos[0]
+
+	switch args[0] {
+	case "lock":
+		opts := secureops.LockOptions{Reason: *reason, TTL: *ttl, Persistent: !*hold}
+		if err := actionError(secureops.LockFileWith(path, c.config.OutDir, opts)); err != nil {
+			return c.fail("Erreur", err)
+		}
+		if *hold {
+			return c.holdLock(path)
+		}
+		locked := true
+		return c.done(secureResult{Path: path, Locked: &locked}, func() {
+			fmt.Println("Fichier verrouillé avec succès.")
+		})
+
+	case "unlock":
+		if err := actionError(secureops.UnlockFile(path, c.config.OutDir)); err != nil {
+			return c.fail("Erreur", err)
+		}
+		locked := false
+		return c.done(secureResult{Path: path, Locked: &locked}, func() {
+			fmt.Println("Fichier déverrouillé avec succès.")
+		})
+
+	case "break":
+		info, err := secureops.BreakStaleLock(path, c.config.OutDir)
+		if err = actionError(err); err != nil {
+			return c.fail("Verrou conservé", err)
+		}
+		locked := false
+		return c.done(secureResult{Path: path, Locked: &locked}, func() {
+			fmt.Printf("Verrou périmé supprimé (%s).\n", info.Owner())
+		})
+
+	case "flock":
+		if len(command) == 0 {
+			return c.usageError("commande manquante (secure flock PATH -- CMD [ARGS...])")
+		}
+		mode := secureops.Exclusive
+		if *shared {
+			mode = secureops.Shared
+		}
+		var lock *secureops.Lock
+		switch {
+		case *block:
+			lock, err = secureops.Acquire(path, mode)
+		case *wait > 0:
+			lock, err = secureops.AcquireTimeout(path, mode, *wait)
+		default:
+			lock, err = secureops.TryAcquire(path, mode)
+		}
+		if err != nil {
+			return c.fail("Verrou indisponible", err)
+		}
+		defer lock.Release()
+		if lock.Mode() != mode {
+			fmt.Fprintln(os.Stderr, "Avertissement : verrou partagé indisponible sur ce système, verrou exclusif pris")
+		}
+
+		stdout := io.Writer(os.Stdout)
+		if c.format == outputJSON {
+			stdout = os.Stderr
+		}
+		result, err := runMeasured(c.config, 0, stdout, os.Stderr, command[0], command[1:]...)
+		if err != nil {
+			return c.fail("Erreur exécution", err)
+		}
+		code := c.done(result, func() { fmt.Printf("Verrou %s sur %s — ", lock.Mode(), path); printRunResult(result) })
+		if result.ExitCode != 0 {
+			return exitError
+		}
+		return code
+
+	case "readonly":
+		ro := !*off
+		err := secureops.SetReadOnly(path, ro)
+		audit(c.config, "file.readonly", absPath(path), secureops.Params{"read_only": ro}, err)
+		if err != nil {
+			return c.fail("Erreur", err)
+		}
+		return c.done(secureResult{Path: path, ReadOnly: &ro}, func() {
+			status := "désactivée"
+			if ro {
+				status = "activée"
+			}
+			fmt.Println("Attribut Lecture Seule", status)
+		})
+
+	default:
+		return c.usageError("sous-commande secure inconnue '%s'", args[0])
+	}
+}
+
+// holdLock garde le verrou posé par secure lock --hold jusqu'à SIGINT ou
+// SIGTERM, puis le lève. Tué autrement, l'outil laisse un verrou périmé
+// que secure break peut supprimer.
+func (c *cli) holdLock(path string) int {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	progress := os.Stdout
+	if c.format == outputJSON {
+		progress = os.Stderr
+	}
+	fmt.Fprintf(progress, "Fichier verrouillé (PID %d), Ctrl+C pour le libérer.\n", os.Getpid())
+	<-sigs
+
+	if err := actionError(secureops.UnlockFile(path, c.config.OutDir)); err != nil {
+		return c.fail("Erreur", err)
+	}
+	locked := false
+	return c.done(secureResult{Path: path, Locked: &locked}, func() {
+		fmt.Println("Fichier déverrouillé avec succès.")
+	})
+}
+
+// Sous-commande SysOps
+func (c *cli) runSys(args []string) int {
+	fs := flag.NewFlagSet("sys", flag.ContinueOnError)
+	sample := fs.Duration("sample", cpuSample, "Durée de mesure de l'utilisation CPU")
+	if _, err := parseArgs(fs, args); err != nil {
+		return c.usageError("%v", err)
+	}
+	overview, err := sysops.Collect(*sample)
+	if err != nil {
+		return c.fail("Erreur vue système", err)
+	}
+	return c.done(overview, func() { printOverview(overview) })
+}
+
+// Résultat JSON d'une liste de sockets (jamais null)
+func newSocketsResult(sockets []procops.Socket) socketsResult {
+	if sockets == nil {
+		sockets = []procops.Socket{}
+	}
+	return socketsResult{Count: len(sockets), Sockets: sockets}
+}
+
+// Résultat JSON d'une liste de processus (jamais null)
+func newProcessesResult(procs []procops.ProcessInfo) processesResult {
+	if procs == nil {
+		procs = []procops.ProcessInfo{}
+	}
+	return processesResult{Count: len(procs), Processes: procs}
+}
+
+// Affiche un tableau de processus
+func printProcesses(procs []procops.ProcessInfo) {
+	if len(procs) == 0 {
+		fmt.Println("Aucun processus trouvé.")
+		return
+	}
+	fmt.Printf("%-7s | %-7s | %-10s | %-1s | %9s | %10s | %-30s\n", "PID", "PPID", "USER", "S", "RSS", "CPU", "NOM")
+	fmt.Println("---------------------------------------------------------------------------------------")
+	for _, p := range procs {
+		fmt.Printf("%-7d | %-7d | %-10.10s | %-1s | %9s | %10s | %-30s\n",
+			p.PID, p.PPID, p.User, p.State, formatBytes(p.RSS), formatDuration(p.CPUTime), p.Name)
+	}
+}
+
+// processTree construit l'arbre complet (query vide), le sous-arbre d'un PID
+// ou ceux des processus dont le nom contient le mot-clé
+func processTree(query string) ([]*procops.ProcessNode, error) {
+	all, err := procops.ListProcesses(procops.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if query == "" {
+		return procops.BuildTree(all), nil
+	}
+	if pid, err := strconv.Atoi(query); err == nil {
+		node, err := procops.Subtree(all, pid)
+		if err != nil {
+			return nil, err
+		}
+		return []*procops.ProcessNode{node}, nil
+	}
+
+	matches, err := procops.FilterProcesses(query)
+	if err != nil {
+		return nil, err
+	}
+	var roots []*procops.ProcessNode
+	for _, m := range matches {
+		// Le processus a pu se terminer entre les deux lectures
+		if node, err := procops.Subtree(all, m.PID); err == nil {
+			roots = append(roots, node)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("aucun processus ne correspond à '%s'", query)
+	}
+	return roots, nil
+}
+
+// Affiche un tableau de sockets en écoute
+func printSockets(sockets []procops.Socket) {
+	if len(sockets) == 0 {
+		fmt.Println("Aucune socket en écoute trouvée.")
+		return
+	}
+	fmt.Printf("%-5s | %-22s | %-6s | %-7s | %-10s | %-20s\n", "PROTO", "ADRESSE", "PORT", "PID", "USER", "PROCESSUS")
+	fmt.Println("--------------------------------------------------------------------------------------")
+	for _, s := range sockets {
+		pid, user, name := "?", "", "(inconnu)"
+		if s.PID > 0 {
+			pid = strconv.Itoa(s.PID)
+		}
+		if s.Process != nil {
+			user, name = s.Process.User, s.Process.Name
+		}
+		fmt.Printf("%-5s | %-22s | %-6d | %-7s | %-10.10s | %-20s\n", s.Proto, s.LocalAddr, s.LocalPort, pid, user, name)
+	}
+}
+
+// Affiche les descripteurs d'un processus et leur répartition par type
+func printFDs(pid int, fds []procops.FileDescriptor) {
+	counts := procops.CountByKind(fds)
+	fmt.Printf("Processus %d : %d descripteurs (fichiers %d, sockets %d, pipes %d, périphériques %d, anon %d)\n",
+		pid, len(fds), counts[procops.FDFile], counts[procops.FDSocket], counts[procops.FDPipe],
+		counts[procops.FDDevice], counts[procops.FDAnon])
+	fmt.Printf("%-5s | %-7s | %-4s | %-50s\n", "FD", "TYPE", "MODE", "CIBLE")
+	fmt.Println("--------------------------------------------------------------------------")
+	for _, fd := range fds {
+		fmt.Printf("%-5d | %-7s | %-4s | %-50s\n", fd.FD, fd.Kind, fd.Mode, fd.Target)
+	}
+}
+
+// Affiche les processus qui ont un fichier ouvert
+func printHolders(path string, holders []procops.FileHolder) {
+	if len(holders) == 0 {
+		fmt.Println("Aucun processus n'a ouvert", path)
+		return
+	}
+	fmt.Printf("%-7s | %-10s | %-20s | %-5s | %-4s\n", "PID", "USER", "PROCESSUS", "FD", "MODE")
+	fmt.Println("------------------------------------------------------------")
+	for _, h := range holders {
+		fmt.Printf("%-7d | %-10.10s | %-20s | %-5d | %-4s\n", h.Process.PID, h.Process.User, h.Process.Name, h.FD.FD, h.FD.Mode)
+	}
+}
+
+// diffSnapshots compare le relevé before au relevé after[0], ou à l'état
+// courant si after est vide
+func diffSnapshots(before string, after []string, topN int) (procops.SnapshotDiff, error) {
+	a, err := procops.LoadSnapshot(before)
+	if err != nil {
+		return procops.SnapshotDiff{}, err
+	}
+	var b procops.Snapshot
+	if len(after) > 0 {
+		b, err = procops.LoadSnapshot(after[0])
+	} else {
+		b, err = procops.TakeSnapshot()
+	}
+	if err != nil {
+		return procops.SnapshotDiff{}, err
+	}
+	return procops.DiffSnapshots(a, b, topN), nil
+}
+
+// Affiche la comparaison de deux relevés
+func printSnapshotDiff(d procops.SnapshotDiff) {
+	fmt.Printf("Du %s au %s (%s)\n\n", d.From.Format("2006-01-02 15:04:05"), d.To.Format("2006-01-02 15:04:05"),
+		d.To.Sub(d.From).Round(time.Second))
+
+	fmt.Printf("Démarrés (%d) :\n", len(d.Started))
+	printProcesses(d.Started)
+	fmt.Printf("\nTerminés (%d) :\n", len(d.Exited))
+	printProcesses(d.Exited)
+
+	fmt.Println("\nPlus fortes hausses de mémoire :")
+	fmt.Printf("%-7s | %-20s | %10s | %10s | %11s\n", "PID", "NOM", "AVANT", "APRÈS", "ÉCART")
+	fmt.Println("--------------------------------------------------------------------")
+	for _, m := range d.MemGrowth {
+		fmt.Printf("%-7d | %-20.20s | %10s | %10s | %11s\n", m.Process.PID, m.Process.Name,
+			formatBytes(m.RSSBefore), formatBytes(m.Process.RSS), "+"+formatBytes(uint64(m.RSSDelta)))
+	}
+
+	fmt.Println("\nPlus gros consommateurs de CPU sur la période :")
+	fmt.Printf("%-7s | %-20s | %10s | %6s\n", "PID", "NOM", "CPU", "%CPU")
+	fmt.Println("------------------------------------------------------")
+	for _, c := range d.CPUGrowth {
+		fmt.Printf("%-7d | %-20.20s | %10s | %6.1f\n", c.Process.PID, c.Process.Name, formatDuration(c.CPUDelta), c.CPUPercent)
+	}
+}
+
+// listInCgroup liste les processus, restreints à un cgroup si prefix est défini
+func listInCgroup(prefix string, opts procops.ListOptions) ([]procops.ProcessInfo, error) {
+	if prefix == "" {
+		return procops.ListProcesses(opts)
+	}
+	procs, err := procops.FindProcesses(procops.Filter{Cgroup: prefix})
+	if err != nil {
+		return nil, err
+	}
+	procops.SortProcesses(procs, opts.SortBy, opts.Order)
+	if opts.TopN > 0 && len(procs) > opts.TopN {
+		procs = procs[:opts.TopN]
+	}
+	return procs, nil
+}
+
+// Affiche les processus groupés par cgroup avec les compteurs v2
+func printCgroups(groups []procops.CgroupGroup) {
+	if len(groups) == 0 {
+		fmt.Println("Aucun cgroup trouvé (Linux avec le backend /proc uniquement).")
+		return
+	}
+	for _, g := range groups {
+		title := g.Path
+		if g.Container != "" {
+			title += " [conteneur " + g.Container + "]"
+		}
+		fmt.Println(title)
+		if g.Stats != nil {
+			fmt.Printf("  mémoire %s, CPU %s (user %s, sys %s), %d tâche(s)\n",
+				formatBytes(g.Stats.MemoryCurrent), formatDuration(g.Stats.CPUUsage),
+				formatDuration(g.Stats.CPUUser), formatDuration(g.Stats.CPUSystem), g.Stats.PIDsCurrent)
+		}
+		for _, p := range g.Processes {
+			fmt.Printf("  %-7d %-10.10s %s\n", p.PID, p.User, p.Name)
+		}
+	}
+}
+
+// Affiche les verrous et leur état
+func printLocks(locks []lockEntry) {
+	if len(locks) == 0 {
+		fmt.Println("Aucun verrou.")
+		return
+	}
+	fmt.Printf("%-30s | %-28s | %-19s | %-8s | %s\n", "FICHIER", "PROPRIÉTAIRE", "DEPUIS", "TTL", "ÉTAT")
+	fmt.Println("----------------------------------------------------------------------------------------------------------")
+	for _, l := range locks {
+		target := l.Path
+		if target == "" {
+			target = strings.TrimSuffix(filepath.Base(l.LockFile), ".lock")
+		}
+		ttl := "-"
+		if l.TTLSec > 0 {
+			ttl = (time.Duration(l.TTLSec) * time.Second).String()
+		}
+		state := "actif"
+		if l.Stale {
+			state = "périmé : " + l.StaleReason
+		}
+		if l.Reason != "" {
+			state += " — " + l.Reason
+		}
+		fmt.Printf("%-30s | %-28.28s | %-19s | %-8s | %s\n", target, l.Owner,
+			l.AcquiredAt.Local().Format("2006-01-02 15:04:05"), ttl, state)
+	}
+
+	legacy := 0
+	for _, l := range locks {
+		if l.Legacy {
+			legacy++
+		}
+	}
+	if legacy > 0 {
+		fmt.Printf("\nAttention : %d ancien(s) verrou(s) sans chemin. Ils ne protègent plus aucun fichier ;\n", legacy)
+		fmt.Println("vérifier le fichier concerné, le reverrouiller (secure lock CHEMIN) puis supprimer l'ancien marqueur (secure break NOM).")
+	}
+}
+
+// Affiche le diagnostic des zombies et des orphelins
+func printZombieReport(r procops.ZombieReport) {
+	if r.Zombies == 0 {
+		fmt.Println("Aucun processus zombie.")
+	} else {
+		fmt.Printf("%d zombie(s) chez %d parent(s) :\n", r.Zombies, len(r.Groups))
+	}
+	for _, g := range r.Groups {
+		pids := make([]string, len(g.Zombies))
+		for i, z := range g.Zombies {
+			pids[i] = strconv.Itoa(z.PID)
+		}
+		fmt.Printf("  Parent %d (%s, %s) : %d zombie(s) [%s]\n",
+			g.Parent.PID, g.Parent.Name, g.Parent.User, len(g.Zombies), strings.Join(pids, " "))
+		fmt.Println("    ->", g.Remediation)
+	}
+
+	fmt.Println()
+	if !r.OrphansChecked {
+		fmt.Println("Orphelins non vérifiés : le backend ne fournit pas les sessions (utiliser process_backend proc).")
+		return
+	}
+	if len(r.Orphans) == 0 {
+		fmt.Println("Aucun orphelin de longue durée.")
+		return
+	}
+	fmt.Printf("%d orphelin(s) de longue durée :\n", len(r.Orphans))
+	fmt.Printf("%-7s | %-10s | %-20s | %-16s | %-7s | %s\n", "PID", "USER", "PROCESSUS", "ADOPTÉ PAR", "SESSION", "ÂGE")
+	fmt.Println("--------------------------------------------------------------------------------")
+	for _, o := range r.Orphans {
+		fmt.Printf("%-7d | %-10.10s | %-20s | %-16.16s | %-7d | %s\n", o.Process.PID, o.Process.User, o.Process.Name,
+			fmt.Sprintf("%d %s", o.Reaper.PID, o.Reaper.Name), o.Process.SID, o.Age.Round(time.Second))
+	}
+}
+
+// Affiche la vue système
+func printOverview(o sysops.Overview) {
+	fmt.Println("Hôte :", o.Hostname)
+	fmt.Println("Uptime :", o.Uptime.Round(time.Second))
+	fmt.Printf("Charge : %.2f %.2f %.2f (%d/%d tâches actives)\n",
+		o.Load.Load1, o.Load.Load5, o.Load.Load15, o.Load.Running, o.Load.Total)
+	fmt.Printf("CPU : %.1f %% sur %d cœur(s)\n", o.CPU.UsagePercent, o.CPU.Cores)
+	fmt.Printf("Mémoire : %s utilisés / %s (%s disponibles)\n",
+		formatBytes(o.Memory.Used()), formatBytes(o.Memory.Total), formatBytes(o.Memory.Available))
+	if o.Memory.SwapTotal > 0 {
+		fmt.Printf("Swap : %s utilisés / %s\n",
+			formatBytes(o.Memory.SwapTotal-o.Memory.SwapFree), formatBytes(o.Memory.SwapTotal))
+	}
+	fmt.Println()
+	fmt.Printf("%-25s | %-10s | %10s | %10s | %6s\n", "MONTAGE", "TYPE", "TAILLE", "LIBRE", "UTIL.")
+	fmt.Println("-----------------------------------------------------------------------")
+	for _, d := range o.Disks {
+		fmt.Printf("%-25s | %-10s | %10s | %10s | %5.1f%%\n",
+			d.Mount, d.FSType, formatBytes(d.Total), formatBytes(d.Available), d.UsedPercent)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"go-devops-tool/procops"
+	"go-devops-tool/secureops"
+)
+
+// runCLI exécute une sous-commande et capture sa sortie standard
+func runCLI(t *testing.T, cfg Config, format string, args ...string) (int, string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	code := runCommand(cfg, format, args)
+	os.Stdout = stdout
+	w.Close()
+	return code, <-done
+}
+
+// testConfig prépare un dossier de sortie et un fichier de trois lignes
+func testConfig(t *testing.T) (Config, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := Config{OutDir: filepath.Join(dir, "out"), BaseDir: dir}
+	if err := os.MkdirAll(cfg.OutDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(input, []byte("un\ndeux\ntrois\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return cfg, input
+}
+
+// decodeEnvelope vérifie qu'une sortie JSON est un unique document versionné
+func decodeEnvelope(t *testing.T, out string) envelope {
+	t.Helper()
+	var env envelope
+	dec := json.NewDecoder(strings.NewReader(out))
+	if err := dec.Decode(&env); err != nil {
+		t.Fatalf("sortie non JSON %q : %v", out, err)
+	}
+	if dec.More() {
+		t.Fatalf("plusieurs documents JSON : %q", out)
+	}
+	if env.SchemaVersion != schemaVersion {
+		t.Errorf("schema_version = %d", env.SchemaVersion)
+	}
+	return env
+}
+
+func TestFileNegativeLines(t *testing.T) {
+	cfg, input := testConfig(t)
+	for _, op := range []string{"head", "tail"} {
+		code, out := runCLI(t, cfg, outputJSON, "file", op, input, "-n", "-2")
+		if code != exitUsage {
+			t.Errorf("file %s -n -2 : code %d, attendu %d", op, code, exitUsage)
+		}
+		if env := decodeEnvelope(t, out); env.OK || env.Error == "" {
+			t.Errorf("file %s -n -2 : %+v", op, env)
+		}
+	}
+}
+
+func TestFileTailMoreThanFile(t *testing.T) {
+	cfg, input := testConfig(t)
+	outFile := filepath.Join(cfg.OutDir, "tail.txt")
+	code, _ := runCLI(t, cfg, outputText, "file", "tail", input, "-n", "100", "-o", outFile)
+	if code != exitOK {
+		t.Fatalf("code %d", code)
+	}
+	if data, _ := os.ReadFile(outFile); !strings.HasPrefix(string(data), "un\ndeux\ntrois\n") {
+		t.Errorf("tail = %q", data)
+	}
+}
+
+func TestSuperviseJSON(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGINT non disponible")
+	}
+	cfg, _ := testConfig(t)
+	cfg.Services = []procops.ServiceSpec{{Name: "dormeur", Command: "sleep", Args: []string{"30"}}}
+
+	// Ctrl+C simulé une fois le service lancé (journal de sortie créé)
+	logFile := filepath.Join(cfg.OutDir, "services", "dormeur.out.log")
+	go func() {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			if _, err := os.Stat(logFile); err == nil {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		self, _ := os.FindProcess(os.Getpid())
+		self.Signal(os.Interrupt)
+	}()
+
+	code, out := runCLI(t, cfg, outputJSON, "proc", "supervise")
+	if code != exitOK {
+		t.Fatalf("code %d : %s", code, out)
+	}
+	env := decodeEnvelope(t, out)
+	if !env.OK || env.Command != "proc.supervise" {
+		t.Fatalf("enveloppe = %+v", env)
+	}
+	services, _ := env.Data.([]any)
+	if len(services) != 1 {
+		t.Fatalf("services = %v", env.Data)
+	}
+	svc := services[0].(map[string]any)
+	if svc["name"] != "dormeur" || svc["state"] != procops.StateStopped || svc["last_pid"] == nil {
+		t.Errorf("bilan = %v", svc)
+	}
+}
+
+func TestSecureAuditFailureIsWarning(t *testing.T) {
+	cfg, input := testConfig(t)
+	t.Cleanup(func() { auditWarnings = nil })
+	// audit.log est un dossier : le journal ne peut pas être écrit
+	if err := os.Mkdir(filepath.Join(cfg.OutDir, "audit.log"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{{"secure", "lock", input}, {"secure", "readonly", input}, {"secure", "unlock", input}} {
+		code, out := runCLI(t, cfg, outputJSON, args...)
+		if code != exitAudit {
+			t.Errorf("%v : code %d, attendu %d", args, code, exitAudit)
+		}
+		if env := decodeEnvelope(t, out); !env.OK || len(env.Warnings) == 0 {
+			t.Errorf("%v : %+v", args, env)
+		}
+		if args[1] == "lock" && !secureops.IsLocked(input, cfg.OutDir) {
+			t.Error("verrou non posé")
+		}
+	}
+}
+
+func TestPkillProtectedIsAudited(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep requis")
+	}
+	cfg, _ := testConfig(t)
+	cfg.Protection = procops.Protection{Names: []string{"sleep"}}
+	procops.SetProtection(cfg.Protection)
+	t.Cleanup(func() { procops.SetProtection(procops.Protection{}) })
+
+	cmd := exec.Command("sleep", "300")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+
+	// Tous les sleep sont protégés : aucun processus visé, tous refusés
+	code, out := runCLI(t, cfg, outputJSON, "proc", "pkill", "sleep", "--confirm", "0")
+	if code != exitError {
+		t.Errorf("code %d, attendu %d : %s", code, exitError, out)
+	}
+	decodeEnvelope(t, out)
+
+	var found bool
+	for _, e := range readAuditLog(t, cfg.OutDir) {
+		if e.Target == strconv.Itoa(cmd.Process.Pid) {
+			found = e.Action == "proc.kill" && e.Outcome == secureops.OutcomeFailure && e.Params["protected"] == true
+		}
+	}
+	if !found {
+		t.Errorf("refus de %d non journalisé", cmd.Process.Pid)
+	}
+	if err := cmd.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Errorf("processus protégé touché : %v", err)
+	}
+}
+
+// readAuditLog relit les enregistrements de OutDir/audit.log
+func readAuditLog(t *testing.T, outDir string) []secureops.AuditEntry {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(outDir, "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []secureops.AuditEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var e secureops.AuditEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("ligne non JSON %q : %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestLoadConfigRejectsUnsafeServiceName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{"out_dir": "out", "services": [{"name": "../../evil", "command": "true"}]}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), "nom invalide") {
+		t.Errorf("config acceptée : %v", err)
+	}
+}
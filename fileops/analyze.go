@@ -21,6 +21,12 @@ func CheckFile(path string) error {
 
 // Infos sur le fichier : taille en octets et nombre de lignes
 func FileInfo(path string) (int64, int, error) {
+	lock, err := lockInput(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer lock.Release()
+
 	file, err := os.Open(path)
 	if err != nil {
 		return 0, 0, err
@@ -42,6 +48,12 @@ func FileInfo(path string) (int64, int, error) {
 
 // Statistiques mots : nombre de mots (ignore les nombres) et longueur moyenne
 func WordStats(path string) (int, float64, error) {
+	lock, err := lockInput(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer lock.Release()
+
 	file, err := os.Open(path)
 	if err != nil {
 		return 0, 0, err
@@ -72,6 +84,12 @@ func WordStats(path string) (int, float64, error) {
 
 // Compte les lignes contenant un mot-clé
 func CountLinesWithKeyword(path, keyword string) (int, error) {
+	lock, err := lockInput(path)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Release()
+
 	file, err := os.Open(path)
 	if err != nil {
 		return 0, err
@@ -90,13 +108,22 @@ func CountLinesWithKeyword(path, keyword string) (int, error) {
 
 // Filtre les lignes contenant ou ne contenant pas le mot-clé
 func FilterLines(path, keyword, outFile string, include bool) error {
+	if err := checkDistinct(path, outFile); err != nil {
+		return err
+	}
+	lock, err := lockInput(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	out, err := os.Create(outFile)
+	out, err := createOutput(outFile)
 	if err != nil {
 		return err
 	}
@@ -114,13 +141,22 @@ func FilterLines(path, keyword, outFile string, include bool) error {
 
 // N premières lignes → head.txt
 func Head(path string, N int, outFile string) error {
+	if err := checkDistinct(path, outFile); err != nil {
+		return err
+	}
+	lock, err := lockInput(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	out, err := os.Create(outFile)
+	out, err := createOutput(outFile)
 	if err != nil {
 		return err
 	}
@@ -137,6 +173,15 @@ func Head(path string, N int, outFile string) error {
 
 // N dernières lignes → tail.txt
 func Tail(path string, N int, outFile string) error {
+	if err := checkDistinct(path, outFile); err != nil {
+		return err
+	}
+	lock, err := lockInput(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	file, err := os.ReadFile(path)
 	if err != nil {
 		return err
@@ -147,8 +192,11 @@ func Tail(path string, N int, outFile string) error {
 	if start < 0 {
 		start = 0
 	}
+	if start > len(lines) {
+		start = len(lines)
+	}
 
-	out, err := os.Create(outFile)
+	out, err := createOutput(outFile)
 	if err != nil {
 		return err
 	}
@@ -5,13 +5,22 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
-// BatchWordStats : analyse les mots de tous les .txt et retourne un rapport
-func BatchWordStats(dir string, outDir string) error {
-	var report strings.Builder
-	report.WriteString("=== Batch FileOps Report ===\n\n")
+// FileStats regroupe les statistiques d'un fichier .txt du lot
+type FileStats struct {
+	Path          string    `json:"path"`
+	Name          string    `json:"name"`
+	SizeBytes     int64     `json:"size_bytes"`
+	ModTime       time.Time `json:"mod_time"`
+	Words         int       `json:"words"`
+	AvgWordLength float64   `json:"avg_word_length"`
+}
 
+// BatchStats : calcule les statistiques de tous les .txt du répertoire
+func BatchStats(dir string) ([]FileStats, error) {
+	var stats []FileStats
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -21,21 +30,39 @@ func BatchWordStats(dir string, outDir string) error {
 			if err != nil {
 				return err
 			}
-			report.WriteString(fmt.Sprintf(
-				"Fichier: %s\nMots: %d\nLongueur moyenne: %.2f\n\n",
-				info.Name(),
-				words,
-				avg,
-			))
+			stats = append(stats, FileStats{
+				Path:          path,
+				Name:          info.Name(),
+				SizeBytes:     info.Size(),
+				ModTime:       info.ModTime(),
+				Words:         words,
+				AvgWordLength: avg,
+			})
 		}
 		return nil
 	})
+	return stats, err
+}
 
+// BatchWordStats : analyse les mots de tous les .txt et retourne un rapport
+func BatchWordStats(dir string, outDir string) error {
+	stats, err := BatchStats(dir)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(outDir, "report.txt"), []byte(report.String()), 0644)
+	var report strings.Builder
+	report.WriteString("=== Batch FileOps Report ===\n\n")
+	for _, st := range stats {
+		report.WriteString(fmt.Sprintf(
+			"Fichier: %s\nMots: %d\nLongueur moyenne: %.2f\n\n",
+			st.Name,
+			st.Words,
+			st.AvgWordLength,
+		))
+	}
+
+	return writeOutput(filepath.Join(outDir, "report.txt"), []byte(report.String()))
 }
 
 // BatchIndex : génère un index des fichiers .txt (chemin, taille, date)
@@ -57,23 +84,33 @@ func BatchIndex(dir string, outFile string) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(outFile, []byte(index.String()), 0644)
+	return writeOutput(outFile, []byte(index.String()))
 }
 
 // BatchMerge : fusionne tous les fichiers .txt dans un seul fichier
 func BatchMerge(dir string, outFile string) error {
-	out, err := os.Create(outFile)
+	out, err := createOutput(outFile)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
+	outInfo, err := out.Stat()
+	if err != nil {
+		return err
+	}
 
 	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".txt") {
+		// Le fichier de fusion peut se trouver dans le dossier parcouru
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".txt") && !os.SameFile(info, outInfo) {
+			lock, err := lockInput(path)
+			if err != nil {
+				return err
+			}
 			content, err := os.ReadFile(path)
+			lock.Release()
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,141 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go-devops-tool/secureops"
+)
+
+// Intervalle entre deux vérifications d'un verrou secure lock
+const lockPollInterval = 100 * time.Millisecond
+
+// Respect des verrous : marqueurs posés par secure lock dans lockDir, et
+// flock(2) posés par d'autres programmes (secure flock, flock(1)...).
+// Sans appel à UseLocks, seuls les flock sont respectés, sans attente.
+var (
+	lockDir  string
+	lockWait time.Duration
+)
+
+// UseLocks indique le dossier des marqueurs de verrou (OutDir) et le délai
+// d'attente d'un fichier verrouillé avant de refuser l'opération (0 = refus immédiat)
+func UseLocks(outDir string, wait time.Duration) {
+	lockDir, lockWait = outDir, wait
+}
+
+// output est un fichier de sortie détenu sous flock exclusif
+type output struct {
+	*os.File
+	lock *secureops.Lock
+}
+
+// Close ferme le fichier puis libère le verrou
+func (o *output) Close() error {
+	err := o.File.Close()
+	if lerr := o.lock.Release(); err == nil {
+		err = lerr
+	}
+	return err
+}
+
+// createOutput refuse d'écrire un fichier protégé par secure lock, pose un
+// flock exclusif puis seulement vide le fichier, pour ne rien perdre en cas de refus
+func createOutput(outFile string) (*output, error) {
+	if err := waitUnlocked(outFile); err != nil {
+		return nil, err
+	}
+	lock, err := acquire(outFile, secureops.Exclusive)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(outFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		lock.Release()
+		return nil, err
+	}
+	return &output{File: f, lock: lock}, nil
+}
+
+// writeOutput équivaut à os.WriteFile en respectant les verrous
+func writeOutput(outFile string, data []byte) error {
+	out, err := createOutput(outFile)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// lockInput pose un flock partagé sur un fichier lu : la lecture attend
+// qu'un programme qui le réécrit ait terminé. Le fichier doit exister.
+// Sans verrou partagé (Windows), rien n'est posé : le marqueur exclusif
+// bloquerait les lectures simultanées et échouerait dans un dossier en
+// lecture seule. Le verrou retourné peut donc être nil (Release reste sûr).
+func lockInput(path string) (*secureops.Lock, error) {
+	if err := CheckFile(path); err != nil {
+		return nil, err
+	}
+	if !secureops.SharedLocks {
+		return nil, nil
+	}
+	return acquire(path, secureops.Shared)
+}
+
+// errSameFile est retournée quand la sortie désigne le fichier lu
+var errSameFile = errors.New("entrée et sortie identiques")
+
+// checkDistinct refuse une sortie qui désigne le fichier d'entrée, avant
+// toute prise de verrou : le verrou exclusif de la sortie attendrait sinon
+// le verrou partagé de l'entrée, détenu par la même commande
+func checkDistinct(input, outFile string) error {
+	in, err := os.Stat(input)
+	if err != nil {
+		return err
+	}
+	out, err := os.Stat(outFile)
+	if err != nil {
+		// Sortie absente : elle sera créée, distincte de l'entrée
+		return nil
+	}
+	if os.SameFile(in, out) {
+		return fmt.Errorf("%s : %w", outFile, errSameFile)
+	}
+	return nil
+}
+
+// acquire pose un flock en attendant au plus lockWait
+func acquire(path string, mode secureops.LockMode) (*secureops.Lock, error) {
+	if lockWait > 0 {
+		return secureops.AcquireTimeout(path, mode, lockWait)
+	}
+	return secureops.TryAcquire(path, mode)
+}
+
+// waitUnlocked attend au plus lockWait qu'aucun verrou secure lock valide
+// ne protège path
+func waitUnlocked(path string) error {
+	if lockDir == "" {
+		return nil
+	}
+	deadline := time.Now().Add(lockWait)
+	for {
+		info, locked := secureops.ActiveLock(path, lockDir)
+		if !locked {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			err := fmt.Errorf("%s : %w par %s", path, secureops.ErrLocked, info.Owner())
+			if info.Reason != "" {
+				err = fmt.Errorf("%w (%s)", err, info.Reason)
+			}
+			return err
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
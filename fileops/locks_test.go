@@ -0,0 +1,184 @@
+package fileops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-devops-tool/secureops"
+)
+
+// useLocks active le respect des verrous pour un test
+func useLocks(t *testing.T, outDir string, wait time.Duration) {
+	t.Helper()
+	UseLocks(outDir, wait)
+	t.Cleanup(func() { UseLocks("", 0) })
+}
+
+// setup crée un fichier d'entrée de trois lignes et une sortie existante
+func setup(t *testing.T) (dir, in, out string) {
+	dir = t.TempDir()
+	in = filepath.Join(dir, "in.txt")
+	out = filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(in, []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(out, []byte("à conserver\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, in, out
+}
+
+func TestHeadRefusesLockedOutput(t *testing.T) {
+	dir, in, out := setup(t)
+	useLocks(t, dir, 0)
+	if err := secureops.LockFileWith(out, dir, secureops.LockOptions{Reason: "test", Persistent: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Head(in, 2, out); !errors.Is(err, secureops.ErrLocked) {
+		t.Fatalf("ErrLocked attendue, obtenu %v", err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "à conserver\n" {
+		t.Errorf("sortie verrouillée modifiée : %q", data)
+	}
+
+	if err := secureops.UnlockFile(out, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := Head(in, 2, out); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "a\nb\n" {
+		t.Errorf("sortie = %q", data)
+	}
+}
+
+func TestTailWaitsForFlock(t *testing.T) {
+	dir, in, out := setup(t)
+	useLocks(t, dir, 2*time.Second)
+	held, err := secureops.Acquire(out, secureops.Exclusive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(100*time.Millisecond, func() { held.Release() })
+
+	if err := Tail(in, 1, out); err != nil {
+		t.Fatalf("sortie non obtenue après libération : %v", err)
+	}
+}
+
+func TestBatchMergeSkipsOutput(t *testing.T) {
+	dir, _, out := setup(t)
+	useLocks(t, t.TempDir(), 0)
+	if err := BatchMerge(dir, out); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	if want := "--- FICHIER: in.txt ---\na\nb\nc\n\n\n"; string(data) != want {
+		t.Errorf("fusion = %q, attendu %q", data, want)
+	}
+}
+
+func TestOutputInsideOutDir(t *testing.T) {
+	dir, in, _ := setup(t)
+	outDir := filepath.Join(dir, "out")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	useLocks(t, outDir, 0)
+	out := filepath.Join(outDir, "head.txt")
+
+	// Le flock de la sortie ne doit pas passer pour un marqueur secure lock
+	if err := Head(in, 1, out); err != nil {
+		t.Fatal(err)
+	}
+	if secureops.IsLocked(out, outDir) {
+		t.Error("sortie vue comme verrouillée après écriture")
+	}
+
+	if err := secureops.LockFileWith(out, outDir, secureops.LockOptions{Persistent: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Head(in, 2, out); !errors.Is(err, secureops.ErrLocked) {
+		t.Fatalf("ErrLocked attendue, obtenu %v", err)
+	}
+	if err := secureops.UnlockFile(out, outDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := Head(in, 2, out); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "a\nb\n" {
+		t.Errorf("sortie = %q", data)
+	}
+
+	// Aucun marqueur résiduel dans OutDir
+	markers, _ := filepath.Glob(filepath.Join(outDir, "*lock"))
+	if len(markers) != 0 {
+		t.Errorf("marqueurs restants : %v", markers)
+	}
+}
+
+func TestSameInputOutput(t *testing.T) {
+	dir, in, _ := setup(t)
+	useLocks(t, dir, 2*time.Second)
+	ops := map[string]func() error{
+		"head":   func() error { return Head(in, 1, in) },
+		"tail":   func() error { return Tail(in, 1, in) },
+		"filtre": func() error { return FilterLines(in, "a", in, true) },
+	}
+	for name, op := range ops {
+		start := time.Now()
+		if err := op(); !errors.Is(err, errSameFile) {
+			t.Errorf("%s : errSameFile attendue, obtenu %v", name, err)
+		}
+		// Refus immédiat, sans attendre lock_wait_sec
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("%s : refus après %s", name, d)
+		}
+	}
+	if data, _ := os.ReadFile(in); string(data) != "a\nb\nc\n" {
+		t.Errorf("entrée modifiée : %q", data)
+	}
+}
+
+func TestConcurrentReaders(t *testing.T) {
+	_, in, _ := setup(t)
+	first, err := lockInput(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Release()
+	second, err := lockInput(in)
+	if err != nil {
+		t.Fatalf("seconde lecture refusée : %v", err)
+	}
+	second.Release()
+}
+
+func TestBatchStatsHonorsWriteLock(t *testing.T) {
+	if !secureops.SharedLocks {
+		t.Skip("entrées non verrouillées sans verrou partagé")
+	}
+	dir, in, _ := setup(t)
+	useLocks(t, t.TempDir(), 0)
+	held, err := secureops.Acquire(in, secureops.Exclusive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BatchStats(dir); !errors.Is(err, secureops.ErrLocked) {
+		t.Errorf("ErrLocked attendue, obtenu %v", err)
+	}
+	held.Release()
+
+	stats, err := BatchStats(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Errorf("statistiques = %+v", stats)
+	}
+}
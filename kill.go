@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-devops-tool/procops"
+	"go-devops-tool/secureops"
+)
+
+// Délai de grâce entre TERM et KILL
+func killGrace(cfg Config) time.Duration {
+	if cfg.KillGraceSec <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(cfg.KillGraceSec) * time.Second
+}
+
+// killProcess envoie le signal demandé ou, si signal est vide, TERM puis KILL
+// après le délai de grâce. Succès, échecs et refus de protection sont journalisés.
+func killProcess(cfg Config, pid int, signal string) (procops.KillResult, error) {
+	if signal == "" {
+		grace := killGrace(cfg)
+		result, err := procops.KillGraceful(pid, grace)
+		params := secureops.Params{"grace_sec": grace.Seconds()}
+		if err == nil {
+			params["signal"], params["escalated"] = result.Signal, result.Escalated
+		}
+		auditKill(cfg, "proc.kill", pid, params, err)
+		return result, err
+	}
+
+	sig, err := procops.ParseSignal(signal)
+	if err != nil {
+		return procops.KillResult{PID: pid}, err
+	}
+	result := procops.KillResult{PID: pid, Signal: procops.SignalName(sig)}
+	err = procops.SendSignal(pid, sig)
+	auditKill(cfg, "proc.signal", pid, secureops.Params{"signal": result.Signal}, err)
+	return result, err
+}
+
+// Journalise un kill ; un refus de protection est distingué d'un échec
+func auditKill(cfg Config, action string, pid int, params secureops.Params, err error) {
+	var protected *procops.ProtectedError
+	if errors.As(err, &protected) {
+		params["protected"] = true
+	}
+	audit(cfg, action, strconv.Itoa(pid), params, err)
+}
+
+// Description lisible du résultat d'un kill
+func describeKill(result procops.KillResult, escalation bool) string {
+	if !escalation {
+		return fmt.Sprintf("Signal %s envoyé au processus %d.", result.Signal, result.PID)
+	}
+	if result.Escalated {
+		return fmt.Sprintf("Processus %d toujours actif après TERM : terminé par KILL (%s).",
+			result.PID, result.Elapsed.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("Processus %d terminé proprement par TERM (%s).",
+		result.PID, result.Elapsed.Round(time.Millisecond))
+}
+
+// killOutcome est le résultat d'un kill au sein d'un kill par motif
+type killOutcome struct {
+	Process procops.ProcessInfo `json:"process"`
+	Result  procops.KillResult  `json:"result"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// matchKillTargets retourne les processus visés par un motif, en mettant de
+// côté ceux que la politique de protection interdit de tuer
+func matchKillTargets(filter procops.Filter) (targets, protected []procops.ProcessInfo, err error) {
+	procs, err := procops.FindProcesses(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, p := range procs {
+		if procops.CheckProtected(p) != nil {
+			protected = append(protected, p)
+			continue
+		}
+		targets = append(targets, p)
+	}
+	return targets, protected, nil
+}
+
+// auditRefused journalise le refus de chaque processus protégé visé par un
+// kill par motif confirmé
+func auditRefused(cfg Config, protected []procops.ProcessInfo, signal string) {
+	for _, p := range protected {
+		params := secureops.Params{}
+		if signal != "" {
+			params["signal"] = signal
+		}
+		auditKill(cfg, "proc.kill", p.PID, params, procops.CheckProtected(p))
+	}
+}
+
+// killMatching envoie le signal (ou l'escalade) à tous les processus en
+// parallèle ; chaque kill est journalisé par killProcess
+func killMatching(cfg Config, targets []procops.ProcessInfo, signal string) []killOutcome {
+	outcomes := make([]killOutcome, len(targets))
+	var wg sync.WaitGroup
+	for i, p := range targets {
+		wg.Add(1)
+		go func(i int, p procops.ProcessInfo) {
+			defer wg.Done()
+			result, err := killProcess(cfg, p.PID, signal)
+			outcomes[i] = killOutcome{Process: p, Result: result}
+			if err != nil {
+				outcomes[i].Error = err.Error()
+			}
+		}(i, p)
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// Affiche le bilan d'un kill par motif, refus de protection compris
+func printKillOutcomes(outcomes []killOutcome, protected []procops.ProcessInfo, escalation bool) {
+	for _, p := range protected {
+		fmt.Println("Refusé :", procops.CheckProtected(p))
+	}
+	failed := 0
+	for _, o := range outcomes {
+		if o.Error != "" {
+			failed++
+			fmt.Printf("Échec %d (%s) : %s\n", o.Process.PID, o.Process.Name, o.Error)
+			continue
+		}
+		fmt.Println(describeKill(o.Result, escalation))
+	}
+	fmt.Printf("%d processus traités, %d échec(s), %d refus.\n", len(outcomes), failed, len(protected))
+}
@@ -2,20 +2,20 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"go-devops-tool/fileops"   // FileOps
 	"go-devops-tool/procops"   // ProcOps
 	"go-devops-tool/secureops" // SecureOps
-
-	"github.com/PuerkitoBio/goquery" // WebOps
+	"go-devops-tool/sysops"    // SysOps
 )
 
 // Structure de configuration
@@ -25,6 +25,27 @@ type Config struct {
 	OutDir      string `json:"out_dir"`
 	DefaultExt  string `json:"default_ext"`
 	ProcessTopN int    `json:"process_top_n"`
+	// Source de la liste des processus : "auto", "proc" ou "ps"
+	ProcessBackend string `json:"process_backend"`
+	// Tri de la liste : cpu, mem, rss, start, pid, name (défaut cpu) et ordre asc/desc
+	ProcessSort  string `json:"process_sort"`
+	ProcessOrder string `json:"process_order"`
+	// Intervalle de rafraîchissement de la vue temps réel (secondes, défaut 2)
+	ProcessRefreshSec int `json:"process_refresh_sec"`
+	// Délai de grâce entre TERM et KILL (secondes, défaut 5)
+	KillGraceSec int `json:"kill_grace_sec"`
+	// Délai maximal par défaut des commandes lancées par proc run (secondes, 0 = aucun)
+	RunTimeoutSec int `json:"run_timeout_sec"`
+	// Attente d'un fichier verrouillé avant d'y renoncer (secondes, 0 = refus immédiat)
+	LockWaitSec int `json:"lock_wait_sec"`
+	// Processus qu'on refuse de tuer (PID 1 et l'outil le sont toujours)
+	Protection procops.Protection `json:"protection"`
+	// Âge minimal d'un orphelin pour être signalé (secondes, défaut 3600)
+	OrphanMinAgeSec int `json:"orphan_min_age_sec"`
+	// Seuils de surveillance des processus (alertes dans OutDir/alerts.log)
+	Alerts []procops.AlertRule `json:"alerts"`
+	// Commandes lancées et redémarrées par l'outil (sorties dans OutDir/services)
+	Services []procops.ServiceSpec `json:"services"`
 }
 
 // Chargement de la configuration JSON
@@ -41,9 +62,53 @@ func loadConfig(path string) (Config, error) {
 	if err != nil {
 		return cfg, err
 	}
+	if err := procops.ValidateServices(cfg.Services); err != nil {
+		return cfg, err
+	}
 	return cfg, nil
 }
 
+// Nombre de processus affichés par défaut
+func processTopN(cfg Config) int {
+	if cfg.ProcessTopN == 0 {
+		return 10
+	}
+	return cfg.ProcessTopN
+}
+
+// Attente d'un fichier verrouillé par les opérations FileOps
+func lockWait(cfg Config) time.Duration {
+	if cfg.LockWaitSec <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.LockWaitSec) * time.Second
+}
+
+// Âge à partir duquel un orphelin est signalé
+func orphanMinAge(cfg Config) time.Duration {
+	if cfg.OrphanMinAgeSec <= 0 {
+		return time.Hour
+	}
+	return time.Duration(cfg.OrphanMinAgeSec) * time.Second
+}
+
+// Options de liste des processus issues de la configuration
+func processListOptions(cfg Config) (procops.ListOptions, error) {
+	sortBy := cfg.ProcessSort
+	if sortBy == "" {
+		sortBy = string(procops.SortCPU)
+	}
+	key, err := procops.ParseSortKey(sortBy)
+	if err != nil {
+		return procops.ListOptions{}, err
+	}
+	order, err := procops.ParseOrder(cfg.ProcessOrder)
+	if err != nil {
+		return procops.ListOptions{}, err
+	}
+	return procops.ListOptions{TopN: processTopN(cfg), SortBy: key, Order: order}, nil
+}
+
 // Menu principal
 func showMenu() {
 	fmt.Println("====== MENU PRINCIPAL ======")
@@ -52,6 +117,7 @@ func showMenu() {
 	fmt.Println("3. WebOps - Wikipédia")
 	fmt.Println("4. ProcOps - Processus (à implémenter)")
 	fmt.Println("5. SecureOps - Sécurité (à implémenter)")
+	fmt.Println("6. SysOps - Vue système")
 	fmt.Println("0. Quitter")
 	fmt.Print("Votre choix : ")
 }
@@ -75,6 +141,22 @@ func showProcOpsMenu() {
 	fmt.Println("1. Lister les processus (Top N)")
 	fmt.Println("2. Rechercher un processus")
 	fmt.Println("3. Tuer un processus (Kill)")
+	fmt.Println("4. Vue temps réel (top)")
+	fmt.Println("5. Arbre des processus")
+	fmt.Println("6. Tuer par motif (nom, regex, ligne de commande)")
+	fmt.Println("7. Ports en écoute")
+	fmt.Println("8. Quel processus écoute sur un port ?")
+	fmt.Println("9. Fichiers ouverts d'un processus")
+	fmt.Println("10. Qui a ouvert ce fichier ?")
+	fmt.Println("11. Services : statut")
+	fmt.Println("12. Services : démarrer")
+	fmt.Println("13. Services : arrêter")
+	fmt.Println("14. Exécuter une commande (délai max, coût mesuré)")
+	fmt.Println("15. Surveiller les seuils (alertes)")
+	fmt.Println("16. Zombies et orphelins")
+	fmt.Println("17. Enregistrer un relevé des processus")
+	fmt.Println("18. Comparer deux relevés")
+	fmt.Println("19. Processus par cgroup / conteneur")
 	fmt.Println("0. Retour")
 	fmt.Print("Votre choix : ")
 }
@@ -85,6 +167,10 @@ func showSecureOpsMenu() {
 	fmt.Println("1. Verrouiller un fichier (.lock)")
 	fmt.Println("2. Déverrouiller un fichier")
 	fmt.Println("3. Basculer Lecture Seule (Windows)")
+	fmt.Println("4. Qui utilise ce fichier ?")
+	fmt.Println("5. Exécuter une commande sous verrou (flock)")
+	fmt.Println("6. Lister les verrous")
+	fmt.Println("7. Supprimer un verrou périmé")
 	fmt.Println("0. Retour")
 	fmt.Print("Votre choix : ")
 }
@@ -92,25 +178,60 @@ func showSecureOpsMenu() {
 func main() {
 	// Gestion du flag --config
 	configPath := flag.String("config", "config.json", "Chemin vers le fichier de configuration")
+	outputFormat := flag.String("output", outputText, "Format de sortie des sous-commandes : json ou text")
+	flag.Usage = usage
 	flag.Parse()
 
 	// Chargement de la config
 	config, err := loadConfig(*configPath)
 	if err != nil {
-		fmt.Println("Erreur chargement config:", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "Erreur chargement config:", err)
+		os.Exit(exitError)
+	}
+
+	// Création du dossier out si inexistant
+	err = os.MkdirAll(config.OutDir, os.ModePerm)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Erreur création dossier out :", err)
+		os.Exit(exitError)
+	}
+
+	// Anciens verrous nommés d'après le seul nom de fichier : renommés d'après le chemin
+	if _, err := secureops.MigrateLocks(config.OutDir); actionError(err) != nil {
+		fmt.Fprintln(os.Stderr, "Erreur migration des verrous :", err)
+	}
+	// Ancien journal d'audit au format texte : mis de côté dans audit-legacy.log
+	if err := secureops.MigrateAuditLog(config.OutDir); err != nil {
+		fmt.Fprintln(os.Stderr, "Erreur migration du journal d'audit :", err)
+	}
+	// Les opérations FileOps refusent d'écrire un fichier verrouillé
+	fileops.UseLocks(config.OutDir, lockWait(config))
+
+	// Sélection du backend de lecture des processus
+	procBackend, err := procops.NewBackend(config.ProcessBackend)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Erreur config :", err)
+		os.Exit(exitError)
+	}
+	procops.SetBackend(procBackend)
+	procops.SetProtection(config.Protection)
+
+	// Mode non interactif : une sous-commande est fournie
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(config, *outputFormat, flag.Args()))
 	}
 
 	fmt.Println("Configuration chargée avec succès")
 	fmt.Println("Fichier par défaut:", config.DefaultFile)
 	fmt.Println()
 
-	// Création du dossier out si inexistant
-	err = os.MkdirAll(config.OutDir, os.ModePerm)
+	// Services supervisés pendant la session interactive
+	supervisor, err := newSupervisor(config, nil)
 	if err != nil {
-		fmt.Println("Erreur création dossier out :", err)
-		os.Exit(1)
+		fmt.Println("Erreur config services :", err)
+		os.Exit(exitError)
 	}
+	defer supervisor.StopAll()
 
 	reader := bufio.NewReader(os.Stdin)
 
@@ -284,57 +405,14 @@ func main() {
 				break
 			}
 
-			url := "https://fr.wikipedia.org/wiki/" + article
-			client := &http.Client{}
-			req, _ := http.NewRequest("GET", url, nil)
-			req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) "+
-				"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36")
-
-			resp, err := client.Do(req)
-			if err != nil {
-				fmt.Println("Erreur HTTP :", err)
-				break
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != 200 {
-				fmt.Println("Page non trouvée, code :", resp.StatusCode)
-				break
-			}
-
-			doc, err := goquery.NewDocumentFromReader(resp.Body)
+			text, err := fetchWikiArticle(article)
 			if err != nil {
-				fmt.Println("Erreur parsing :", err)
-				break
-			}
-
-			text := ""
-			doc.Find("div#mw-content-text div.mw-parser-output p").Each(func(i int, s *goquery.Selection) {
-				p := strings.TrimSpace(s.Text())
-				if p != "" {
-					text += p + "\n"
-				}
-			})
-
-			if text == "" {
-				fmt.Println("Aucun texte trouvé dans l'article !")
+				fmt.Println("Erreur :", err)
 				break
 			}
 
 			// Stats mots
-			words := strings.Fields(text)
-			totalWords, totalLength := 0, 0
-			for _, w := range words {
-				if _, err := strconv.Atoi(w); err == nil {
-					continue
-				}
-				totalWords++
-				totalLength += len(w)
-			}
-			avgLength := 0.0
-			if totalWords > 0 {
-				avgLength = float64(totalLength) / float64(totalWords)
-			}
+			totalWords, avgLength := textWordStats(text)
 
 			fmt.Println("Stats de l'article :")
 			fmt.Println("Nombre de mots :", totalWords)
@@ -352,20 +430,29 @@ func main() {
 
 				switch pchoice {
 				case 1: // List
-					topN := config.ProcessTopN
-					if topN == 0 {
-						topN = 10
+					opts, err := processListOptions(config)
+					if err != nil {
+						fmt.Println("Erreur config :", err)
+						break
+					}
+
+					fmt.Printf("Trier par (cpu, mem, rss, start, pid, name) [%s] : ", opts.SortBy)
+					sortBy, _ := reader.ReadString('\n')
+					if sortBy = strings.TrimSpace(sortBy); sortBy != "" {
+						key, err := procops.ParseSortKey(sortBy)
+						if err != nil {
+							fmt.Println("Erreur :", err)
+							break
+						}
+						opts.SortBy, opts.Order = key, ""
 					}
-					procs, err := procops.ListProcesses(topN)
+
+					procs, err := procops.ListProcesses(opts)
 					if err != nil {
 						fmt.Println("Erreur liste :", err)
 						break
 					}
-					fmt.Printf("%-10s | %-30s\n", "PID", "NOM")
-					fmt.Println("-------------------------------------------")
-					for _, p := range procs {
-						fmt.Printf("%-10s | %-30s\n", p.PID, p.Name)
-					}
+					printProcesses(procs)
 
 				case 2: // Filter
 					fmt.Print("Rechercher (nom) : ")
@@ -377,42 +464,323 @@ func main() {
 						fmt.Println("Erreur recherche :", err)
 						break
 					}
-					if len(procs) == 0 {
-						fmt.Println("Aucun processus trouvé.")
-					} else {
-						fmt.Printf("%-10s | %-30s\n", "PID", "NOM")
-						fmt.Println("-------------------------------------------")
-						for _, p := range procs {
-							fmt.Printf("%-10s | %-30s\n", p.PID, p.Name)
-						}
-					}
+					printProcesses(procs)
 
 				case 3: // Kill
 					fmt.Print("PID du processus à tuer : ")
-					pid, _ := reader.ReadString('\n')
-					pid = strings.TrimSpace(pid)
+					input, _ := reader.ReadString('\n')
+					input = strings.TrimSpace(input)
 
-					if pid == "" {
+					if input == "" {
 						fmt.Println("PID vide !")
 						break
 					}
+					pid, err := strconv.Atoi(input)
+					if err != nil {
+						fmt.Println("PID invalide :", input)
+						break
+					}
 
-					fmt.Printf("Êtes-vous sûr de vouloir tuer le PID %s ? (yes/no) : ", pid)
+					fmt.Printf("Signal (%s) ou vide pour TERM puis KILL après %s : ",
+						strings.Join(procops.SignalNames(), ", "), killGrace(config))
+					signal, _ := reader.ReadString('\n')
+					signal = strings.TrimSpace(signal)
+					action := "TERM puis KILL"
+					if signal != "" {
+						sig, err := procops.ParseSignal(signal)
+						if err != nil {
+							fmt.Println("Erreur :", err)
+							break
+						}
+						action = procops.SignalName(sig)
+					}
+
+					fmt.Printf("Êtes-vous sûr de vouloir envoyer %s au PID %d ? (yes/no) : ", action, pid)
 					confirm, _ := reader.ReadString('\n')
 					confirm = strings.TrimSpace(strings.ToLower(confirm))
 
 					if confirm == "yes" {
-						err := procops.KillProcess(pid)
+						result, err := killProcess(config, pid, signal)
 						if err != nil {
 							fmt.Println("Erreur lors du kill :", err)
 						} else {
-							fmt.Println("Processus", pid, "tué avec succès.")
-							secureops.LogAction(config.OutDir, "Kill processus: "+pid)
+							fmt.Println(describeKill(result, signal == ""))
 						}
 					} else {
 						fmt.Println("Action annulée.")
 					}
 
+				case 4: // Watch
+					opts, err := processListOptions(config)
+					if err != nil {
+						fmt.Println("Erreur config :", err)
+						break
+					}
+					if err := runProcWatch(reader, opts, processRefresh(config)); err != nil {
+						fmt.Println("Erreur vue temps réel :", err)
+					}
+
+				case 5: // Tree
+					fmt.Print("PID ou nom du processus (vide = arbre complet) : ")
+					query, _ := reader.ReadString('\n')
+					query = strings.TrimSpace(query)
+
+					roots, err := processTree(query)
+					if err != nil {
+						fmt.Println("Erreur arbre :", err)
+						break
+					}
+					procops.RenderTree(os.Stdout, roots)
+
+				case 6: // Kill par motif
+					fmt.Print("Motif (nom ou expression régulière) : ")
+					pattern, _ := reader.ReadString('\n')
+					pattern = strings.TrimSpace(pattern)
+					if pattern == "" {
+						fmt.Println("Motif vide !")
+						break
+					}
+
+					fmt.Print("Expression régulière ? (y/n) : ")
+					resp, _ := reader.ReadString('\n')
+					isRegex := strings.TrimSpace(strings.ToLower(resp)) == "y"
+					fmt.Print("Chercher dans la ligne de commande complète ? (y/n) : ")
+					resp, _ = reader.ReadString('\n')
+					inCmdline := strings.TrimSpace(strings.ToLower(resp)) == "y"
+
+					targets, protected, err := matchKillTargets(procops.Filter{Pattern: pattern, Regex: isRegex, Cmdline: inCmdline})
+					if err != nil {
+						fmt.Println("Erreur recherche :", err)
+						break
+					}
+					for _, p := range protected {
+						fmt.Println("Ignoré :", procops.CheckProtected(p))
+					}
+					// Simulation obligatoire : on montre ce qui serait touché
+					fmt.Println("Simulation — processus visés :")
+					printProcesses(targets)
+					if len(targets) == 0 {
+						// Rien à confirmer : la demande ne vise que des processus protégés
+						auditRefused(config, protected, "")
+						break
+					}
+
+					fmt.Printf("Signal (%s) ou vide pour TERM puis KILL après %s : ",
+						strings.Join(procops.SignalNames(), ", "), killGrace(config))
+					signal, _ := reader.ReadString('\n')
+					signal = strings.TrimSpace(signal)
+					if signal != "" {
+						if _, err := procops.ParseSignal(signal); err != nil {
+							fmt.Println("Erreur :", err)
+							break
+						}
+					}
+
+					fmt.Printf("Pour confirmer, tapez le nombre de processus visés (%d) : ", len(targets))
+					confirm, _ := reader.ReadString('\n')
+					if strings.TrimSpace(confirm) != strconv.Itoa(len(targets)) {
+						fmt.Println("Action annulée.")
+						break
+					}
+					outcomes := killMatching(config, targets, signal)
+					auditRefused(config, protected, signal)
+					printKillOutcomes(outcomes, protected, signal == "")
+
+				case 7: // Ports en écoute
+					sockets, err := procops.ListeningSockets()
+					if err != nil {
+						fmt.Println("Erreur sockets :", err)
+						break
+					}
+					printSockets(sockets)
+
+				case 8: // Port -> processus
+					fmt.Print("Port : ")
+					input, _ := reader.ReadString('\n')
+					port, err := strconv.Atoi(strings.TrimSpace(input))
+					if err != nil || port <= 0 || port > 65535 {
+						fmt.Println("Port invalide !")
+						break
+					}
+					sockets, err := procops.PortOwners(port)
+					if err != nil {
+						fmt.Println("Erreur sockets :", err)
+						break
+					}
+					if len(sockets) == 0 {
+						fmt.Printf("Aucun processus n'écoute sur le port %d.\n", port)
+						break
+					}
+					printSockets(sockets)
+
+				case 9: // Descripteurs d'un processus
+					fmt.Print("PID du processus : ")
+					input, _ := reader.ReadString('\n')
+					pid, err := strconv.Atoi(strings.TrimSpace(input))
+					if err != nil {
+						fmt.Println("PID invalide !")
+						break
+					}
+					fds, err := procops.OpenFiles(pid)
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printFDs(pid, fds)
+
+				case 10: // Qui a ouvert ce fichier
+					fmt.Print("Chemin du fichier : ")
+					path, _ := reader.ReadString('\n')
+					path = strings.TrimSpace(path)
+
+					holders, err := procops.WhoHasOpen(path)
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printHolders(path, holders)
+
+				case 11: // Statut des services
+					printServices(supervisor.Status())
+
+				case 12, 13: // Démarrer / arrêter un service
+					names := supervisor.Names()
+					if len(names) == 0 {
+						fmt.Println("Aucun service défini dans la config.")
+						break
+					}
+					fmt.Printf("Service (%s, ou 'tous') : ", strings.Join(names, ", "))
+					name, _ := reader.ReadString('\n')
+					name = strings.TrimSpace(name)
+					if name != "tous" {
+						names = []string{name}
+					}
+
+					for _, n := range names {
+						var err error
+						if pchoice == 12 {
+							err = supervisor.Start(n)
+						} else {
+							err = supervisor.Stop(n)
+						}
+						if err != nil {
+							fmt.Println("Erreur :", err)
+						}
+					}
+					printServices(supervisor.Status())
+
+				case 14: // Exécution mesurée
+					fmt.Print("Commande : ")
+					line, _ := reader.ReadString('\n')
+					fields := strings.Fields(line)
+					if len(fields) == 0 {
+						fmt.Println("Commande vide !")
+						break
+					}
+					timeout := runTimeout(config)
+					fmt.Printf("Délai max (ex: 30s, 0 = aucun) [%s] : ", timeout)
+					input, _ := reader.ReadString('\n')
+					if input = strings.TrimSpace(input); input != "" {
+						d, err := time.ParseDuration(input)
+						if err != nil {
+							fmt.Println("Durée invalide !")
+							break
+						}
+						timeout = d
+					}
+
+					result, err := runMeasured(config, timeout, os.Stdout, os.Stderr, fields[0], fields[1:]...)
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printRunResult(result)
+
+				case 15: // Surveillance des seuils jusqu'à q
+					// Règles validées avant de lire l'entrée
+					mon, err := newMonitor(config, true)
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					restore, raw := rawTerminal()
+					ctx, cancel := context.WithCancel(context.Background())
+					quit := watchQuit(reader, raw)
+					go func() {
+						<-quit
+						cancel()
+					}()
+					hint := "q pour arrêter la surveillance"
+					if !raw {
+						hint = "q puis Entrée pour arrêter la surveillance"
+					}
+					fmt.Println(hint)
+					alerts, err := runMonitor(ctx, config, mon, processRefresh(config), true)
+					if err != nil {
+						// watchQuit lit encore l'entrée : on attend q pour la rendre au menu
+						fmt.Println("Erreur :", err, "—", hint)
+						<-quit
+					}
+					cancel()
+					restore()
+					if err != nil {
+						break
+					}
+					fmt.Printf("%d alerte(s) levée(s).\n", len(alerts))
+
+				case 16: // Diagnostic zombies / orphelins
+					report, err := procops.DiagnoseZombies(orphanMinAge(config))
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printZombieReport(report)
+
+				case 17: // Relevé
+					snap, err := procops.TakeSnapshot()
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					path, err := procops.SaveSnapshot(config.OutDir, snap)
+					if err != nil {
+						fmt.Println("Erreur sauvegarde :", err)
+						break
+					}
+					fmt.Printf("%d processus enregistrés dans %s\n", len(snap.Processes), path)
+
+				case 18: // Comparaison de relevés
+					paths, _ := procops.ListSnapshots(config.OutDir)
+					for _, p := range paths {
+						fmt.Println(" ", p)
+					}
+					fmt.Print("Relevé de référence : ")
+					before, _ := reader.ReadString('\n')
+					before = strings.TrimSpace(before)
+					fmt.Print("Relevé à comparer (Entrée = maintenant) : ")
+					after, _ := reader.ReadString('\n')
+					var others []string
+					if after = strings.TrimSpace(after); after != "" {
+						others = []string{after}
+					}
+
+					diff, err := diffSnapshots(before, others, processTopN(config))
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printSnapshotDiff(diff)
+
+				case 19: // Groupes cgroup
+					fmt.Print("Chemin cgroup (Entrée = tous) : ")
+					prefix, _ := reader.ReadString('\n')
+					groups, err := procops.CgroupGroups(strings.TrimSpace(prefix))
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printCgroups(groups)
+
 				case 0:
 					break
 				default:
@@ -435,8 +803,11 @@ func main() {
 					fmt.Print("Chemin du fichier à verrouiller : ")
 					path, _ := reader.ReadString('\n')
 					path = strings.TrimSpace(path)
+					fmt.Print("Raison (facultatif) : ")
+					reason, _ := reader.ReadString('\n')
 
-					if err := secureops.LockFile(path, config.OutDir); err != nil {
+					opts := secureops.LockOptions{Reason: strings.TrimSpace(reason), Persistent: true}
+					if err := actionError(secureops.LockFileWith(path, config.OutDir, opts)); err != nil {
 						fmt.Println("Erreur :", err)
 					} else {
 						fmt.Println("Fichier verrouillé avec succès.")
@@ -447,7 +818,7 @@ func main() {
 					path, _ := reader.ReadString('\n')
 					path = strings.TrimSpace(path)
 
-					if err := secureops.UnlockFile(path, config.OutDir); err != nil {
+					if err := actionError(secureops.UnlockFile(path, config.OutDir)); err != nil {
 						fmt.Println("Erreur :", err)
 					} else {
 						fmt.Println("Fichier déverrouillé avec succès.")
@@ -463,7 +834,9 @@ func main() {
 					resp = strings.TrimSpace(strings.ToLower(resp))
 					ro := resp == "y"
 
-					if err := secureops.SetReadOnly(path, ro); err != nil {
+					err := secureops.SetReadOnly(path, ro)
+					audit(config, "file.readonly", absPath(path), secureops.Params{"read_only": ro}, err)
+					if err != nil {
 						fmt.Println("Erreur :", err)
 					} else {
 						status := "désactivée"
@@ -471,9 +844,65 @@ func main() {
 							status = "activée"
 						}
 						fmt.Println("Attribut Lecture Seule", status)
-						secureops.LogAction(config.OutDir, fmt.Sprintf("SetReadOnly (%t): %s", ro, path))
 					}
 
+				case 4: // Qui utilise ce fichier
+					fmt.Print("Chemin du fichier : ")
+					path, _ := reader.ReadString('\n')
+					path = strings.TrimSpace(path)
+
+					holders, err := procops.WhoHasOpen(path)
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printHolders(path, holders)
+
+				case 5: // Commande sous verrou
+					fmt.Print("Chemin du fichier à verrouiller : ")
+					path, _ := reader.ReadString('\n')
+					path = strings.TrimSpace(path)
+					fmt.Print("Commande : ")
+					line, _ := reader.ReadString('\n')
+					fields := strings.Fields(line)
+					if len(fields) == 0 {
+						fmt.Println("Commande vide !")
+						break
+					}
+
+					lock, err := secureops.TryAcquire(path, secureops.Exclusive)
+					if err != nil {
+						fmt.Println("Verrou indisponible :", err)
+						break
+					}
+					result, err := runMeasured(config, runTimeout(config), os.Stdout, os.Stderr, fields[0], fields[1:]...)
+					lock.Release()
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printRunResult(result)
+
+				case 6: // Liste des verrous
+					locks, err := secureops.ListLocks(config.OutDir)
+					if err != nil {
+						fmt.Println("Erreur :", err)
+						break
+					}
+					printLocks(newLocksResult(locks).Locks)
+
+				case 7: // Verrou périmé
+					fmt.Print("Chemin du fichier verrouillé : ")
+					path, _ := reader.ReadString('\n')
+					path = strings.TrimSpace(path)
+
+					info, err := secureops.BreakStaleLock(path, config.OutDir)
+					if err = actionError(err); err != nil {
+						fmt.Println("Verrou conservé :", err)
+						break
+					}
+					fmt.Printf("Verrou périmé supprimé (%s).\n", info.Owner())
+
 				case 0:
 					break
 				default:
@@ -485,7 +914,16 @@ func main() {
 				}
 				fmt.Println()
 			}
+		case 6: // SysOps
+			overview, err := sysops.Collect(cpuSample)
+			if err != nil {
+				fmt.Println("Erreur vue système :", err)
+				break
+			}
+			printOverview(overview)
+
 		case 0:
+			supervisor.StopAll()
 			fmt.Println("Au revoir")
 			return
 		default:
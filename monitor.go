@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"go-devops-tool/procops"
+	"go-devops-tool/secureops"
+)
+
+// Fichier dédié aux alertes, dans OutDir
+const alertsFile = "alerts.log"
+
+// newMonitor prépare les règles d'alerte de la config. Chaque alerte est
+// écrite dans le journal d'audit et dans OutDir/alerts.log.
+func newMonitor(cfg Config, echo bool) (*procops.Monitor, error) {
+	if len(cfg.Alerts) == 0 {
+		return nil, fmt.Errorf("aucune règle d'alerte dans la config (section alerts)")
+	}
+	mon, err := procops.NewMonitor(cfg.Alerts)
+	if err != nil {
+		return nil, err
+	}
+	mon.Grace = killGrace(cfg)
+	mon.OnAlert = func(a procops.Alert) {
+		msg := describeAlert(a)
+		params := secureops.Params{"rule": a.Rule, "process": a.Process.Name, "metric": a.Metric,
+			"value": a.Value, "threshold": a.Threshold, "sustained_sec": a.Sustained.Seconds(), "action": a.Action}
+		var actionErr error
+		if a.ActionError != "" {
+			actionErr = errors.New(a.ActionError)
+		}
+		audit(cfg, "proc.alert", strconv.Itoa(a.Process.PID), params, actionErr)
+		if err := appendAlert(cfg.OutDir, a.Time, msg); err != nil {
+			fmt.Fprintln(os.Stderr, "Erreur écriture alerte :", err)
+		}
+		if echo {
+			fmt.Printf("[%s] %s\n", a.Time.Format("15:04:05"), msg)
+		}
+	}
+	return mon, nil
+}
+
+// runMonitor surveille les processus avec le moniteur préparé par newMonitor
+// jusqu'à l'annulation de ctx et retourne les alertes levées
+func runMonitor(ctx context.Context, cfg Config, mon *procops.Monitor, interval time.Duration, echo bool) ([]procops.Alert, error) {
+	alerts := []procops.Alert{}
+	onAlert := mon.OnAlert
+	mon.OnAlert = func(a procops.Alert) {
+		onAlert(a)
+		alerts = append(alerts, a)
+	}
+	if echo {
+		fmt.Printf("Surveillance de %d règle(s) toutes les %s — alertes dans %s\n",
+			len(cfg.Alerts), interval, filepath.Join(cfg.OutDir, alertsFile))
+	}
+	err := mon.Run(ctx, interval)
+	return alerts, err
+}
+
+// appendAlert ajoute une ligne horodatée au fichier des alertes
+func appendAlert(outDir string, t time.Time, msg string) error {
+	f, err := os.OpenFile(filepath.Join(outDir, alertsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "[%s] %s\n", t.Format("2006-01-02 15:04:05"), msg)
+	return err
+}
+
+// Description lisible d'une alerte et de son action
+func describeAlert(a procops.Alert) string {
+	var value, threshold string
+	switch a.Metric {
+	case procops.MetricCPU:
+		value, threshold = fmt.Sprintf("%.1f%%", a.Value), fmt.Sprintf("%.1f%%", a.Threshold)
+	case procops.MetricRSS:
+		value, threshold = formatBytes(uint64(a.Value)), formatBytes(uint64(a.Threshold))
+	case procops.MetricRuntime:
+		value = time.Duration(a.Value * float64(time.Second)).Round(time.Second).String()
+		threshold = time.Duration(a.Threshold * float64(time.Second)).String()
+	default:
+		value, threshold = fmt.Sprintf("%.0f", a.Value), fmt.Sprintf("%.0f", a.Threshold)
+	}
+	msg := fmt.Sprintf("%s : %s (PID %d) %s %s > %s depuis %s, action %s",
+		a.Rule, a.Process.Name, a.Process.PID, a.Metric, value, threshold, a.Sustained.Round(time.Second), a.Action)
+	if a.ActionError != "" {
+		msg += " échouée : " + a.ActionError
+	}
+	return msg
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go-devops-tool/secureops"
+)
+
+// Version du schéma des documents JSON. À incrémenter à chaque changement
+// incompatible (champ renommé ou supprimé) ; l'ajout de champs ne la change pas.
+const schemaVersion = 2
+
+// Formats de sortie acceptés par --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// envelope est le document JSON émis par chaque sous-commande
+type envelope struct {
+	SchemaVersion int    `json:"schema_version"`
+	Command       string `json:"command"`
+	OK            bool   `json:"ok"`
+	Data          any    `json:"data,omitempty"`
+	Error         string `json:"error,omitempty"`
+	// Warnings : échecs d'écriture du journal d'audit (action effectuée)
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// writeJSON écrit un document indenté sur la sortie standard
+func writeJSON(env envelope) {
+	env.SchemaVersion = schemaVersion
+	env.Warnings = auditFailures()
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(env); err != nil {
+		fmt.Fprintln(os.Stderr, "Erreur encodage JSON :", err)
+	}
+}
+
+// Résultats structurés des opérations (noms de champs stables)
+
+type fileInfoResult struct {
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+	Lines     int    `json:"lines"`
+}
+
+type wordStatsResult struct {
+	Path          string  `json:"path"`
+	Words         int     `json:"words"`
+	AvgWordLength float64 `json:"avg_word_length"`
+}
+
+type extractResult struct {
+	Path   string `json:"path"`
+	Lines  int    `json:"lines"`
+	Output string `json:"output"`
+}
+
+type countResult struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Count   int    `json:"count"`
+}
+
+type filterResult struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Include bool   `json:"include"`
+	Output  string `json:"output"`
+}
+
+type batchResult struct {
+	Dir    string `json:"dir"`
+	Files  any    `json:"files"`
+	Report string `json:"report,omitempty"`
+	Index  string `json:"index,omitempty"`
+	Merged string `json:"merged,omitempty"`
+}
+
+type wikiResult struct {
+	Article       string  `json:"article"`
+	Words         int     `json:"words"`
+	AvgWordLength float64 `json:"avg_word_length"`
+	Output        string  `json:"output"`
+}
+
+type processesResult struct {
+	Count     int `json:"count"`
+	Processes any `json:"processes"`
+}
+
+type socketsResult struct {
+	Count   int `json:"count"`
+	Sockets any `json:"sockets"`
+}
+
+type fdsResult struct {
+	PID    int            `json:"pid"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+	FDs    any            `json:"fds"`
+}
+
+type holdersResult struct {
+	Path    string `json:"path"`
+	Count   int    `json:"count"`
+	Holders any    `json:"holders"`
+}
+
+type pkillResult struct {
+	Pattern   string `json:"pattern"`
+	Regex     bool   `json:"regex"`
+	Cmdline   bool   `json:"cmdline"`
+	DryRun    bool   `json:"dry_run"`
+	Count     int    `json:"count"`
+	Targets   any    `json:"targets"`
+	Protected any    `json:"protected"`
+	Outcomes  any    `json:"outcomes,omitempty"`
+}
+
+type snapshotResult struct {
+	Path    string    `json:"path"`
+	TakenAt time.Time `json:"taken_at"`
+	Count   int       `json:"count"`
+}
+
+// lockEntry ajoute au verrou son propriétaire lisible et son état
+type lockEntry struct {
+	secureops.LockInfo
+	LockFile    string `json:"lock_file"`
+	Legacy      bool   `json:"legacy"`
+	Owner       string `json:"owner"`
+	Stale       bool   `json:"stale"`
+	StaleReason string `json:"stale_reason,omitempty"`
+}
+
+type locksResult struct {
+	Count int         `json:"count"`
+	Locks []lockEntry `json:"locks"`
+}
+
+func newLocksResult(locks []secureops.LockInfo) locksResult {
+	now := time.Now()
+	entries := make([]lockEntry, len(locks))
+	for i, l := range locks {
+		stale, reason := l.Stale(now)
+		entries[i] = lockEntry{LockInfo: l, LockFile: l.LockFile, Legacy: l.Legacy,
+			Owner: l.Owner(), Stale: stale, StaleReason: reason}
+	}
+	return locksResult{Count: len(entries), Locks: entries}
+}
+
+type secureResult struct {
+	Path     string `json:"path"`
+	Locked   *bool  `json:"locked,omitempty"`
+	ReadOnly *bool  `json:"read_only,omitempty"`
+}
+
+// formatBytes affiche une taille en unités lisibles (Kio, Mio, Gio)
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d o", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cio", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatDuration affiche un temps CPU au format hh:mm:ss
+func formatDuration(d time.Duration) string {
+	s := int64(d / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, (s/60)%60, s%60)
+}
@@ -0,0 +1,197 @@
+package procops
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Racine par défaut de la hiérarchie cgroup v2
+const defaultCgroupRoot = "/sys/fs/cgroup"
+
+// CgroupStats regroupe les compteurs cgroup v2 d'un groupe
+type CgroupStats struct {
+	MemoryCurrent uint64        `json:"memory_current_bytes"` // memory.current
+	CPUUsage      time.Duration `json:"cpu_usage_ns"`         // cpu.stat usage_usec
+	CPUUser       time.Duration `json:"cpu_user_ns"`          // cpu.stat user_usec
+	CPUSystem     time.Duration `json:"cpu_system_ns"`        // cpu.stat system_usec
+	PIDsCurrent   int           `json:"pids_current"`         // pids.current
+}
+
+// CgroupGroup rassemble les processus d'un même cgroup
+type CgroupGroup struct {
+	Path      string        `json:"path"`
+	Container string        `json:"container,omitempty"`
+	Stats     *CgroupStats  `json:"stats,omitempty"` // absent hors cgroup v2 ou sans droits
+	Processes []ProcessInfo `json:"processes"`
+}
+
+// CgroupFS lit les fichiers d'interface de la hiérarchie cgroup v2
+type CgroupFS struct {
+	Root string // point de montage, "/sys/fs/cgroup" par défaut
+}
+
+// root retourne le point de montage effectif. En mode hybride (v1 + v2),
+// la hiérarchie v2 est montée sous unified.
+func (fs CgroupFS) root() string {
+	if fs.Root != "" {
+		return fs.Root
+	}
+	unified := filepath.Join(defaultCgroupRoot, "unified")
+	if _, err := os.Stat(filepath.Join(unified, "cgroup.controllers")); err == nil {
+		return unified
+	}
+	return defaultCgroupRoot
+}
+
+// Stats lit memory.current, cpu.stat et pids.current du cgroup. Les fichiers
+// absents (contrôleur non activé) laissent le compteur à zéro ; une erreur
+// n'est retournée que si aucun n'est lisible.
+func (fs CgroupFS) Stats(path string) (CgroupStats, error) {
+	dir := filepath.Join(fs.root(), filepath.FromSlash(strings.TrimPrefix(path, "/")))
+	var stats CgroupStats
+	var firstErr error
+	found := false
+
+	if v, err := readUint(filepath.Join(dir, "memory.current")); err == nil {
+		stats.MemoryCurrent, found = v, true
+	} else {
+		firstErr = err
+	}
+	if v, err := readUint(filepath.Join(dir, "pids.current")); err == nil {
+		stats.PIDsCurrent, found = int(v), true
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "cpu.stat")); err == nil {
+		found = true
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) != 2 {
+				continue
+			}
+			usec, _ := strconv.ParseInt(fields[1], 10, 64)
+			d := time.Duration(usec) * time.Microsecond
+			switch fields[0] {
+			case "usage_usec":
+				stats.CPUUsage = d
+			case "user_usec":
+				stats.CPUUser = d
+			case "system_usec":
+				stats.CPUSystem = d
+			}
+		}
+	}
+	if !found {
+		return stats, firstErr
+	}
+	return stats, nil
+}
+
+// readUint lit un fichier contenant un seul entier
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// parseCgroup extrait le chemin du processus de /proc/<pid>/cgroup : la
+// ligne v2 (0::/chemin), ou en mode hybride la première hiérarchie v1 plus
+// précise que la racine
+func parseCgroup(data []byte) string {
+	v2 := ""
+	v1 := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			v2 = parts[2]
+		} else if v1 == "" && parts[2] != "/" {
+			v1 = parts[2]
+		}
+	}
+	if (v2 == "" || v2 == "/") && v1 != "" {
+		return v1
+	}
+	return v2
+}
+
+// Identifiants de conteneurs dans les chemins cgroup : docker-<id>.scope,
+// /docker/<id>, cri-containerd-<id>.scope, crio-<id>, libpod-<id>...
+var containerIDPattern = regexp.MustCompile(`(?:^|[/-])([0-9a-f]{64})(?:\.scope)?$`)
+
+// ContainerID retourne l'identifiant court (12 caractères) du conteneur
+// d'un chemin cgroup, ou une chaîne vide
+func ContainerID(path string) string {
+	m := containerIDPattern.FindStringSubmatch(path)
+	if m == nil {
+		return ""
+	}
+	return m[1][:12]
+}
+
+// InCgroup indique si le chemin cgroup est prefix ou l'un de ses descendants
+func InCgroup(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix == "" {
+		return true
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// GroupByCgroup regroupe les processus par chemin cgroup et lit les
+// compteurs de chaque groupe ; les plus gros consommateurs de mémoire d'abord
+func GroupByCgroup(procs []ProcessInfo, fs CgroupFS) []CgroupGroup {
+	byPath := map[string]*CgroupGroup{}
+	for _, p := range procs {
+		if p.Cgroup == "" {
+			continue
+		}
+		g, ok := byPath[p.Cgroup]
+		if !ok {
+			g = &CgroupGroup{Path: p.Cgroup, Container: p.Container}
+			if stats, err := fs.Stats(p.Cgroup); err == nil {
+				g.Stats = &stats
+			}
+			byPath[p.Cgroup] = g
+		}
+		g.Processes = append(g.Processes, p)
+	}
+
+	groups := make([]CgroupGroup, 0, len(byPath))
+	for _, g := range byPath {
+		groups = append(groups, *g)
+	}
+	memory := func(g CgroupGroup) uint64 {
+		if g.Stats == nil {
+			return 0
+		}
+		return g.Stats.MemoryCurrent
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if memory(groups[i]) != memory(groups[j]) {
+			return memory(groups[i]) > memory(groups[j])
+		}
+		return groups[i].Path < groups[j].Path
+	})
+	return groups
+}
+
+// CgroupGroups regroupe les processus du système par cgroup (Linux)
+func CgroupGroups(prefix string) ([]CgroupGroup, error) {
+	procs, err := FindProcesses(Filter{Cgroup: prefix})
+	if err != nil {
+		return nil, err
+	}
+	return GroupByCgroup(procs, CgroupFS{}), nil
+}
@@ -0,0 +1,93 @@
+package procops
+
+import (
+	"testing"
+	"time"
+)
+
+// Conteneur déclaré dans testdata/proc/201/cgroup
+const fixtureContainer = "3f4e9a2b7c1d"
+
+func TestParseCgroup(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"v2", "0::/system.slice/ssh.service\n", "/system.slice/ssh.service"},
+		{"hybride", "4:memory:/docker/abc\n1:cpu:/\n0::/\n", "/docker/abc"},
+		{"v2 racine seule", "0::/\n", "/"},
+		{"vide", "", ""},
+	}
+	for _, tt := range tests {
+		if got := parseCgroup([]byte(tt.data)); got != tt.want {
+			t.Errorf("%s : parseCgroup = %q, attendu %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	id := fixtureContainer + "0000000000000000000000000000000000000000000000000000"
+	for _, path := range []string{
+		"/system.slice/docker-" + id + ".scope",
+		"/docker/" + id,
+		"/kubepods/burstable/pod1/cri-containerd-" + id + ".scope",
+	} {
+		if got := ContainerID(path); got != fixtureContainer {
+			t.Errorf("ContainerID(%q) = %q", path, got)
+		}
+	}
+	if got := ContainerID("/user.slice/session-3.scope"); got != "" {
+		t.Errorf("aucun conteneur attendu, obtenu %q", got)
+	}
+}
+
+func TestInCgroup(t *testing.T) {
+	if !InCgroup("/system.slice/ssh.service", "/system.slice/") {
+		t.Error("descendant non reconnu")
+	}
+	if InCgroup("/system.slice-extra/x", "/system.slice") {
+		t.Error("préfixe sans séparateur accepté")
+	}
+	if !InCgroup("/a", "") {
+		t.Error("préfixe vide : tout doit correspondre")
+	}
+}
+
+func TestFindProcessesByCgroup(t *testing.T) {
+	useFixture(t)
+
+	procs, err := FindProcesses(Filter{Cgroup: "/system.slice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pids(procs), []int{100, 201, 202}; !equalInts(got, want) {
+		t.Errorf("PIDs = %v, attendu %v", got, want)
+	}
+	if procs[1].Container != fixtureContainer {
+		t.Errorf("conteneur = %q, attendu %q", procs[1].Container, fixtureContainer)
+	}
+}
+
+func TestGroupByCgroup(t *testing.T) {
+	procs, err := ProcFS{Root: fixtureRoot}.Processes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := GroupByCgroup(procs, CgroupFS{Root: "testdata/cgroup"})
+	if len(groups) != 3 {
+		t.Fatalf("%d groupes, attendu 3", len(groups))
+	}
+
+	// Seul le conteneur a des compteurs : il passe en tête
+	g := groups[0]
+	if g.Container != fixtureContainer || !equalInts(pids(g.Processes), []int{201, 202}) {
+		t.Fatalf("premier groupe inattendu : %s %v", g.Path, pids(g.Processes))
+	}
+	want := CgroupStats{MemoryCurrent: 1 << 30, CPUUsage: 90 * time.Second,
+		CPUUser: 80 * time.Second, CPUSystem: 10 * time.Second, PIDsCurrent: 2}
+	if g.Stats == nil || *g.Stats != want {
+		t.Errorf("compteurs = %+v, attendu %+v", g.Stats, want)
+	}
+	if groups[1].Stats != nil {
+		t.Errorf("pas de compteurs attendus pour %s", groups[1].Path)
+	}
+}
@@ -0,0 +1,190 @@
+package procops
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandBackend liste les processus via les outils système
+// (tasklist sous Windows, ps ailleurs)
+type CommandBackend struct{}
+
+// Name retourne le nom du backend
+func (CommandBackend) Name() string {
+	if runtime.GOOS == "windows" {
+		return "tasklist"
+	}
+	return "ps"
+}
+
+// Processes exécute la commande système et analyse sa sortie
+func (CommandBackend) Processes() ([]ProcessInfo, error) {
+	if runtime.GOOS == "windows" {
+		return tasklistProcesses()
+	}
+	return psProcesses()
+}
+
+// Process lit un seul processus (ps -p ou tasklist filtré sur le PID)
+func (CommandBackend) Process(pid int) (ProcessInfo, error) {
+	var procs []ProcessInfo
+	var err error
+	if runtime.GOOS == "windows" {
+		procs, err = tasklistProcesses("/FI", "PID eq "+strconv.Itoa(pid))
+	} else {
+		procs, err = psProcesses("-p", strconv.Itoa(pid))
+	}
+	// ps -p sort avec le code 1 quand le PID n'existe pas
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return ProcessInfo{}, err
+	}
+	for _, p := range procs {
+		if p.PID == pid {
+			return p, nil
+		}
+	}
+	return ProcessInfo{}, notFound(pid)
+}
+
+// psProcesses analyse la sortie de ps (Linux, macOS, BSD) pour les processus
+// sélectionnés (tous par défaut, ou par exemple "-p", "42")
+func psProcesses(selection ...string) ([]ProcessInfo, error) {
+	if len(selection) == 0 {
+		selection = []string{"-A"}
+	}
+	ps := func(columns string) *exec.Cmd {
+		return exec.Command("ps", append(append([]string{}, selection...), "-o", columns)...)
+	}
+	output, err := ps("pid=,ppid=,uid=,user=,state=,rss=,vsz=,time=,etime=,args=").Output()
+	if err != nil {
+		return nil, err
+	}
+	// Deuxième appel pour les noms : comm doit être la dernière colonne
+	// car il peut contenir des espaces, tout comme args
+	names := map[int]string{}
+	if out, err := ps("pid=,comm=").Output(); err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			line = strings.TrimSpace(line)
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				pid, _ := strconv.Atoi(fields[0])
+				names[pid] = baseName(strings.TrimSpace(line[len(fields[0]):]))
+			}
+		}
+	}
+
+	now := time.Now()
+	var processes []ProcessInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 10 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		p := ProcessInfo{PID: pid, User: fields[3], State: fields[4][:1]}
+		p.PPID, _ = strconv.Atoi(fields[1])
+		p.UID, _ = strconv.Atoi(fields[2])
+		rss, _ := strconv.ParseUint(fields[5], 10, 64)
+		vsz, _ := strconv.ParseUint(fields[6], 10, 64)
+		p.RSS, p.VSize = rss*1024, vsz*1024
+		p.CPUTime = parseClock(fields[7])
+		p.StartTime = now.Add(-parseClock(fields[8])).Truncate(time.Second)
+		p.Cmdline = strings.Join(fields[9:], " ")
+		p.Name = names[pid]
+		if p.Name == "" {
+			p.Name = baseName(fields[9])
+		}
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
+
+// tasklistProcesses analyse la sortie CSV détaillée de tasklist (Windows),
+// avec un filtre /FI facultatif
+func tasklistProcesses(filter ...string) ([]ProcessInfo, error) {
+	output, err := exec.Command("tasklist", append([]string{"/V", "/FO", "CSV", "/NH"}, filter...)...).Output()
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bytes.NewReader(output))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []ProcessInfo
+	for _, record := range records {
+		// Image, PID, Session, Session#, Mémoire, Statut, Utilisateur, Temps CPU, Titre
+		if len(record) < 8 {
+			continue
+		}
+		pid, err := strconv.Atoi(record[1])
+		if err != nil {
+			continue
+		}
+		state := "S"
+		if record[5] == "Running" {
+			state = "R"
+		}
+		processes = append(processes, ProcessInfo{
+			PID:     pid,
+			UID:     -1,
+			Name:    record[0],
+			User:    record[6],
+			State:   state,
+			RSS:     parseDigits(record[4]) * 1024,
+			CPUTime: parseClock(record[7]),
+			Cmdline: record[0],
+		})
+	}
+	return processes, nil
+}
+
+// baseName retire le répertoire d'un chemin d'exécutable (comm est un chemin
+// complet sous macOS) sans toucher aux noms de threads noyau comme kworker/0:1
+func baseName(name string) string {
+	if strings.HasPrefix(name, "/") {
+		return filepath.Base(name)
+	}
+	return name
+}
+
+// parseClock convertit une durée au format [jj-][hh:]mm:ss[.cc] en time.Duration
+func parseClock(s string) time.Duration {
+	var total time.Duration
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		days, _ := strconv.Atoi(s[:i])
+		total += time.Duration(days) * 24 * time.Hour
+		s = s[i+1:]
+	}
+	parts := strings.Split(s, ":")
+	unit := time.Second
+	for i := len(parts) - 1; i >= 0; i-- {
+		v, _ := strconv.ParseFloat(parts[i], 64)
+		total += time.Duration(v * float64(unit))
+		unit *= 60
+	}
+	return total
+}
+
+// parseDigits ne garde que les chiffres (ex: "12 345 K" -> 12345)
+func parseDigits(s string) uint64 {
+	var v uint64
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			v = v*10 + uint64(r-'0')
+		}
+	}
+	return v
+}
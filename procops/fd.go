@@ -0,0 +1,169 @@
+package procops
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Types de descripteurs
+const (
+	FDFile   = "file"
+	FDSocket = "socket"
+	FDPipe   = "pipe"
+	FDDevice = "device"
+	FDAnon   = "anon" // anon_inode : eventfd, epoll, inotify...
+)
+
+// FileDescriptor est un descripteur ouvert par un processus
+type FileDescriptor struct {
+	FD     int    `json:"fd"`
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	Mode   string `json:"mode,omitempty"` // r, w ou rw (fdinfo)
+	Pos    int64  `json:"pos"`            // position courante (fdinfo)
+}
+
+// FileHolder associe un processus au descripteur qui référence un fichier
+type FileHolder struct {
+	Process ProcessInfo    `json:"process"`
+	FD      FileDescriptor `json:"fd"`
+}
+
+// OpenFiles lit les descripteurs de /proc/<pid>/fd et leur fdinfo
+func (fs ProcFS) OpenFiles(pid int) ([]FileDescriptor, error) {
+	dir := filepath.Join(fs.root(), strconv.Itoa(pid))
+	entries, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		return nil, err
+	}
+
+	var fds []FileDescriptor
+	for _, e := range entries {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(dir, "fd", e.Name()))
+		if err != nil {
+			// Descripteur fermé entre la lecture du répertoire et celle du lien
+			continue
+		}
+		fd := FileDescriptor{FD: n, Kind: fdKind(target), Target: target}
+		fd.Mode, fd.Pos = readFDInfo(filepath.Join(dir, "fdinfo", e.Name()))
+		fds = append(fds, fd)
+	}
+	sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+	return fds, nil
+}
+
+// CountFDs compte les descripteurs ouverts sans résoudre leurs cibles
+func (fs ProcFS) CountFDs(pid int) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(fs.root(), strconv.Itoa(pid), "fd"))
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// WhoHasOpen parcourt tous les processus lisibles et retourne ceux qui ont
+// le fichier ouvert (chemin absolu, liens symboliques résolus)
+func (fs ProcFS) WhoHasOpen(path string) ([]FileHolder, error) {
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	}
+
+	procs, err := fs.Processes()
+	if err != nil {
+		return nil, err
+	}
+	var holders []FileHolder
+	for _, p := range procs {
+		fds, err := fs.OpenFiles(p.PID)
+		if err != nil {
+			// Droits insuffisants ou processus terminé
+			continue
+		}
+		for _, fd := range fds {
+			if fd.Kind == FDFile && fd.Target == target {
+				holders = append(holders, FileHolder{Process: p, FD: fd})
+			}
+		}
+	}
+	return holders, nil
+}
+
+// CountByKind compte les descripteurs par type
+func CountByKind(fds []FileDescriptor) map[string]int {
+	counts := map[string]int{}
+	for _, fd := range fds {
+		counts[fd.Kind]++
+	}
+	return counts
+}
+
+// OpenFiles liste les descripteurs ouverts d'un processus (Linux)
+func OpenFiles(pid int) ([]FileDescriptor, error) {
+	fds, err := procFS().OpenFiles(pid)
+	if err != nil {
+		return nil, fmt.Errorf("lecture des descripteurs du processus %d : %w", pid, err)
+	}
+	return fds, nil
+}
+
+// WhoHasOpen retourne les processus ayant ouvert le fichier (Linux)
+func WhoHasOpen(path string) ([]FileHolder, error) {
+	return procFS().WhoHasOpen(path)
+}
+
+// fdKind déduit le type d'un descripteur de la cible de son lien
+func fdKind(target string) string {
+	switch {
+	case strings.HasPrefix(target, "socket:"):
+		return FDSocket
+	case strings.HasPrefix(target, "pipe:"):
+		return FDPipe
+	case strings.HasPrefix(target, "anon_inode:"):
+		return FDAnon
+	case strings.HasPrefix(target, "/dev/"):
+		return FDDevice
+	}
+	return FDFile
+}
+
+// readFDInfo extrait le mode d'accès (bits O_ACCMODE des flags octaux) et la
+// position de /proc/<pid>/fdinfo/<fd>
+func readFDInfo(path string) (string, int64) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0
+	}
+	defer f.Close()
+
+	mode, pos := "", int64(0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "pos":
+			pos, _ = strconv.ParseInt(value, 10, 64)
+		case "flags":
+			if flags, err := strconv.ParseUint(value, 8, 64); err == nil {
+				mode = [...]string{"r", "w", "rw", "rw"}[flags&3]
+			}
+		}
+	}
+	return mode, pos
+}
@@ -0,0 +1,235 @@
+package procops
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Actions déclenchées par une alerte
+const (
+	ActionLog    = "log"    // journaliser seulement (défaut)
+	ActionSignal = "signal" // envoyer le signal de la règle
+	ActionKill   = "kill"   // TERM puis KILL après le délai de grâce
+)
+
+// Mesures surveillées
+const (
+	MetricCPU     = "cpu"     // %CPU sur le dernier intervalle
+	MetricRSS     = "rss"     // mémoire résidente (octets)
+	MetricFDs     = "fds"     // descripteurs ouverts (Linux)
+	MetricRuntime = "runtime" // temps écoulé depuis le démarrage (secondes)
+)
+
+// AlertRule définit des seuils pour les processus correspondant au motif.
+// Un seuil à 0 n'est pas surveillé.
+type AlertRule struct {
+	Name       string  `json:"name"`
+	Match      string  `json:"match"` // motif de nom, vide = tous les processus
+	Regex      bool    `json:"regex"`
+	Cmdline    bool    `json:"cmdline"`
+	CPUPercent float64 `json:"cpu_percent"`
+	RSSMB      uint64  `json:"rss_mb"`
+	OpenFDs    int     `json:"open_fds"`
+	RuntimeSec int     `json:"runtime_sec"`
+	SustainSec int     `json:"sustain_sec"` // durée de dépassement continue avant alerte
+	Action     string  `json:"action"`      // log (défaut), signal ou kill
+	Signal     string  `json:"signal"`      // signal de l'action signal (défaut TERM)
+}
+
+// Alert est un dépassement de seuil maintenu pendant la durée de la règle
+type Alert struct {
+	Time        time.Time     `json:"time"`
+	Rule        string        `json:"rule"`
+	Process     ProcessInfo   `json:"process"`
+	Metric      string        `json:"metric"`
+	Value       float64       `json:"value"`
+	Threshold   float64       `json:"threshold"`
+	Sustained   time.Duration `json:"sustained_ns"`
+	Action      string        `json:"action"`
+	ActionError string        `json:"action_error,omitempty"`
+}
+
+// rule est une règle validée, avec son filtre compilé
+type rule struct {
+	AlertRule
+	match  func(ProcessInfo) bool
+	signal string
+}
+
+// breachKey identifie un dépassement : règle, processus et mesure
+type breachKey struct {
+	rule   int
+	proc   sampleKey
+	metric string
+}
+
+// Monitor échantillonne les processus et lève une alerte par épisode de
+// dépassement : une nouvelle alerte n'est possible qu'après retour sous le seuil
+type Monitor struct {
+	rules   []rule
+	sampler *Sampler
+	since   map[breachKey]time.Time // début de chaque dépassement en cours
+	fired   map[breachKey]bool
+	now     func() time.Time // horloge des durées de dépassement
+
+	// Grace est le délai entre TERM et KILL de l'action kill
+	Grace time.Duration
+	// OnAlert, s'il est défini, est appelé pour chaque alerte (action effectuée)
+	OnAlert func(Alert)
+}
+
+// NewMonitor valide les règles (motif, action, signal)
+func NewMonitor(rules []AlertRule) (*Monitor, error) {
+	m := &Monitor{
+		sampler: NewSampler(),
+		since:   map[breachKey]time.Time{},
+		fired:   map[breachKey]bool{},
+		now:     time.Now,
+		Grace:   5 * time.Second,
+	}
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("règle %d", i+1)
+		}
+		if r.CPUPercent <= 0 && r.RSSMB == 0 && r.OpenFDs <= 0 && r.RuntimeSec <= 0 {
+			return nil, fmt.Errorf("alerte %s : aucun seuil défini", r.Name)
+		}
+		match, err := Filter{Pattern: r.Match, Regex: r.Regex, Cmdline: r.Cmdline}.matcher()
+		if err != nil {
+			return nil, fmt.Errorf("alerte %s : %w", r.Name, err)
+		}
+		compiled := rule{AlertRule: r, match: match}
+		switch r.Action {
+		case "":
+			compiled.Action = ActionLog
+		case ActionLog, ActionKill:
+		case ActionSignal:
+			compiled.signal = r.Signal
+			if compiled.signal == "" {
+				compiled.signal = "TERM"
+			}
+			if _, err := ParseSignal(compiled.signal); err != nil {
+				return nil, fmt.Errorf("alerte %s : %w", r.Name, err)
+			}
+		default:
+			return nil, fmt.Errorf("alerte %s : action inconnue '%s' (log, signal, kill)", r.Name, r.Action)
+		}
+		m.rules = append(m.rules, compiled)
+	}
+	return m, nil
+}
+
+// Check prend un échantillon, met à jour les dépassements en cours et retourne
+// les alertes nouvellement levées, après exécution de leur action
+func (m *Monitor) Check() ([]Alert, error) {
+	procs, err := m.sampler.Sample(ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	fs := procFS()
+
+	since := map[breachKey]time.Time{}
+	acted := map[sampleKey]bool{} // une seule action par processus et par échantillon
+	var alerts []Alert
+	for ri, r := range m.rules {
+		for _, p := range procs {
+			if !r.match(p) {
+				continue
+			}
+			for _, b := range r.breaches(fs, p, now) {
+				key := breachKey{rule: ri, proc: sampleKey{pid: p.PID, start: p.StartTime}, metric: b.Metric}
+				start, ok := m.since[key]
+				if !ok {
+					start = now
+				}
+				since[key] = start
+
+				sustained := now.Sub(start)
+				if m.fired[key] || sustained < time.Duration(r.SustainSec)*time.Second {
+					continue
+				}
+				m.fired[key] = true
+				b.Time, b.Rule, b.Process, b.Sustained, b.Action = now, r.Name, p, sustained, r.Action
+				if acted[key.proc] {
+					b.Action = ActionLog
+				} else if err := m.act(r, p); err != nil {
+					b.ActionError = err.Error()
+				}
+				if b.Action != ActionLog {
+					acted[key.proc] = true
+				}
+				alerts = append(alerts, b)
+			}
+		}
+	}
+
+	// Les dépassements terminés sont oubliés : une prochaine alerte redevient possible
+	for key := range m.fired {
+		if _, ok := since[key]; !ok {
+			delete(m.fired, key)
+		}
+	}
+	m.since = since
+	return alerts, nil
+}
+
+// Run appelle Check à chaque intervalle jusqu'à l'annulation de ctx et
+// transmet les alertes à OnAlert
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		alerts, err := m.Check()
+		if err != nil {
+			return err
+		}
+		if m.OnAlert != nil {
+			for _, a := range alerts {
+				m.OnAlert(a)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// breaches retourne les mesures du processus au-dessus des seuils de la règle
+func (r rule) breaches(fs ProcFS, p ProcessInfo, now time.Time) []Alert {
+	var out []Alert
+	if r.CPUPercent > 0 && p.CPUPercent > r.CPUPercent {
+		out = append(out, Alert{Metric: MetricCPU, Value: p.CPUPercent, Threshold: r.CPUPercent})
+	}
+	if limit := r.RSSMB * 1024 * 1024; limit > 0 && p.RSS > limit {
+		out = append(out, Alert{Metric: MetricRSS, Value: float64(p.RSS), Threshold: float64(limit)})
+	}
+	if r.OpenFDs > 0 {
+		// Droits insuffisants ou système sans /proc : mesure ignorée
+		if n, err := fs.CountFDs(p.PID); err == nil && n > r.OpenFDs {
+			out = append(out, Alert{Metric: MetricFDs, Value: float64(n), Threshold: float64(r.OpenFDs)})
+		}
+	}
+	if r.RuntimeSec > 0 && !p.StartTime.IsZero() {
+		if age := now.Sub(p.StartTime); age > time.Duration(r.RuntimeSec)*time.Second {
+			out = append(out, Alert{Metric: MetricRuntime, Value: age.Seconds(), Threshold: float64(r.RuntimeSec)})
+		}
+	}
+	return out
+}
+
+// act exécute l'action de la règle ; les processus protégés sont refusés
+func (m *Monitor) act(r rule, p ProcessInfo) error {
+	switch r.Action {
+	case ActionSignal:
+		sig, _ := ParseSignal(r.signal)
+		return SendSignal(p.PID, sig)
+	case ActionKill:
+		_, err := KillGraceful(p.PID, m.Grace)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package procops
+
+import (
+	"testing"
+	"time"
+)
+
+const mb = 1024 * 1024
+
+// fakeClock fait avancer l'horloge du moniteur à la main
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+// newTestMonitor crée un moniteur sur le backend factice, avec une horloge manuelle
+func newTestMonitor(t *testing.T, rules ...AlertRule) (*Monitor, *fakeBackend, *fakeClock) {
+	t.Helper()
+	fake := useFakeBackend(t)
+	m, err := NewMonitor(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{t: time.Now()}
+	m.now = clock.now
+	return m, fake, clock
+}
+
+func TestNewMonitorValidation(t *testing.T) {
+	for _, r := range []AlertRule{
+		{Name: "sans seuil"},
+		{Name: "action", RSSMB: 1, Action: "reboot"},
+		{Name: "signal", RSSMB: 1, Action: ActionSignal, Signal: "PAS-UN-SIGNAL"},
+		{Name: "motif", RSSMB: 1, Match: "(", Regex: true},
+	} {
+		if _, err := NewMonitor([]AlertRule{r}); err == nil {
+			t.Errorf("%s : erreur attendue", r.Name)
+		}
+	}
+	m, err := NewMonitor([]AlertRule{{RSSMB: 1}, {CPUPercent: 50, Action: ActionSignal}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.rules[0].Name != "règle 1" || m.rules[0].Action != ActionLog || m.rules[1].signal != "TERM" {
+		t.Errorf("valeurs par défaut = %+v", m.rules)
+	}
+}
+
+func TestMonitorSustainOncePerEpisode(t *testing.T) {
+	m, fake, clock := newTestMonitor(t, AlertRule{Name: "fuite", Match: "api", RSSMB: 100, SustainSec: 30})
+	start := clock.t.Add(-time.Hour)
+	set := func(rss uint64) {
+		fake.procs = []ProcessInfo{
+			{PID: 10, Name: "api", StartTime: start, RSS: rss},
+			{PID: 11, Name: "autre", StartTime: start, RSS: 500 * mb}, // hors motif
+		}
+	}
+
+	steps := []struct {
+		at     time.Duration
+		rss    uint64
+		alerts int
+	}{
+		{0, 200 * mb, 0}, // début du dépassement
+		{10 * time.Second, 200 * mb, 0},
+		{30 * time.Second, 200 * mb, 1}, // maintenu 30 s
+		{40 * time.Second, 200 * mb, 0}, // une seule alerte par épisode
+		{50 * time.Second, 50 * mb, 0},  // retour sous le seuil : fin de l'épisode
+		{60 * time.Second, 200 * mb, 0}, // nouvel épisode, la durée repart de zéro
+		{80 * time.Second, 200 * mb, 0},
+		{90 * time.Second, 200 * mb, 1},
+	}
+	base := clock.t
+	for _, s := range steps {
+		clock.t = base.Add(s.at)
+		set(s.rss)
+		alerts, err := m.Check()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(alerts) != s.alerts {
+			t.Fatalf("à %s : %d alertes, attendu %d (%+v)", s.at, len(alerts), s.alerts, alerts)
+		}
+		for _, a := range alerts {
+			if a.Rule != "fuite" || a.Metric != MetricRSS || a.Process.PID != 10 || a.Sustained != 30*time.Second {
+				t.Errorf("à %s : alerte = %+v", s.at, a)
+			}
+			if a.Value != float64(200*mb) || a.Threshold != float64(100*mb) || a.Action != ActionLog {
+				t.Errorf("à %s : mesure = %+v", s.at, a)
+			}
+		}
+	}
+}
+
+func TestMonitorImmediateAndPerMetric(t *testing.T) {
+	m, fake, clock := newTestMonitor(t, AlertRule{Name: "vieux et gros", RSSMB: 100, RuntimeSec: 60})
+	fake.procs = []ProcessInfo{{PID: 20, Name: "batch", StartTime: clock.t.Add(-30 * time.Second), RSS: 200 * mb}}
+
+	// Sans sustain_sec, l'alerte est immédiate ; le temps d'exécution n'est pas encore dépassé
+	alerts, err := m.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Metric != MetricRSS {
+		t.Fatalf("alertes = %+v", alerts)
+	}
+
+	// Chaque mesure a son propre épisode
+	clock.t = clock.t.Add(time.Minute)
+	alerts, err = m.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Metric != MetricRuntime || alerts[0].Value != 90 {
+		t.Fatalf("alertes = %+v", alerts)
+	}
+}
+
+func TestMonitorReusedPIDIsNewEpisode(t *testing.T) {
+	m, fake, clock := newTestMonitor(t, AlertRule{RSSMB: 100})
+	first := clock.t.Add(-time.Hour)
+	fake.procs = []ProcessInfo{{PID: 30, Name: "w", StartTime: first, RSS: 200 * mb}}
+	if alerts, _ := m.Check(); len(alerts) != 1 {
+		t.Fatalf("alertes = %+v", alerts)
+	}
+
+	// Même PID, autre processus : nouvelle alerte sans retour sous le seuil
+	clock.t = clock.t.Add(time.Minute)
+	fake.procs = []ProcessInfo{{PID: 30, Name: "w", StartTime: clock.t.Add(-5 * time.Second), RSS: 200 * mb}}
+	if alerts, _ := m.Check(); len(alerts) != 1 {
+		t.Fatalf("PID réattribué : alertes = %+v", alerts)
+	}
+}
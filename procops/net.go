@@ -0,0 +1,256 @@
+package procops
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Socket est une entrée de /proc/net/{tcp,tcp6,udp,udp6}
+type Socket struct {
+	Proto      string       `json:"proto"` // tcp, tcp6, udp, udp6
+	LocalAddr  string       `json:"local_addr"`
+	LocalPort  int          `json:"local_port"`
+	RemoteAddr string       `json:"remote_addr"`
+	RemotePort int          `json:"remote_port"`
+	State      string       `json:"state"` // LISTEN, ESTABLISHED, UNCONN...
+	UID        int          `json:"uid"`
+	Inode      uint64       `json:"inode"`
+	PID        int          `json:"pid"` // 0 si le propriétaire n'a pas pu être lu (droits)
+	Process    *ProcessInfo `json:"process,omitempty"`
+}
+
+// Fichiers de sockets lus dans <root>/net
+var socketFiles = []string{"tcp", "tcp6", "udp", "udp6"}
+
+// États TCP du noyau (include/net/tcp_states.h)
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// Sockets lit toutes les sockets TCP et UDP (IPv4 et IPv6)
+func (fs ProcFS) Sockets() ([]Socket, error) {
+	var sockets []Socket
+	read := 0
+	for _, proto := range socketFiles {
+		list, err := parseSocketFile(filepath.Join(fs.root(), "net", proto), proto)
+		if err != nil {
+			// tcp6/udp6 sont absents si IPv6 est désactivé
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		read++
+		sockets = append(sockets, list...)
+	}
+	if read == 0 {
+		return nil, fmt.Errorf("aucun fichier de sockets dans %s/net (Linux uniquement)", fs.root())
+	}
+	return sockets, nil
+}
+
+// ListeningSockets retourne les sockets en écoute (TCP LISTEN, UDP non
+// connectées) associées à leur processus propriétaire
+func (fs ProcFS) ListeningSockets() ([]Socket, error) {
+	all, err := fs.Sockets()
+	if err != nil {
+		return nil, err
+	}
+	var listening []Socket
+	for _, s := range all {
+		if s.State == "LISTEN" || s.State == "UNCONN" {
+			listening = append(listening, s)
+		}
+	}
+	if err := fs.attachOwners(listening); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(listening, func(i, j int) bool {
+		if listening[i].LocalPort != listening[j].LocalPort {
+			return listening[i].LocalPort < listening[j].LocalPort
+		}
+		return listening[i].Proto < listening[j].Proto
+	})
+	return listening, nil
+}
+
+// attachOwners renseigne PID et Process en associant les inodes des sockets
+// aux liens socket:[inode] de /proc/<pid>/fd
+func (fs ProcFS) attachOwners(sockets []Socket) error {
+	owners, err := fs.socketOwners()
+	if err != nil {
+		return err
+	}
+	procs, err := fs.Processes()
+	if err != nil {
+		return err
+	}
+	byPID := make(map[int]ProcessInfo, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+	for i := range sockets {
+		pid, ok := owners[sockets[i].Inode]
+		if !ok {
+			continue
+		}
+		sockets[i].PID = pid
+		if p, ok := byPID[pid]; ok {
+			sockets[i].Process = &p
+		}
+	}
+	return nil
+}
+
+// socketOwners construit la table inode -> PID. Les répertoires fd illisibles
+// (processus d'autres utilisateurs sans droits root) sont ignorés.
+func (fs ProcFS) socketOwners() (map[uint64]int, error) {
+	entries, err := os.ReadDir(fs.root())
+	if err != nil {
+		return nil, err
+	}
+	owners := map[uint64]int{}
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join(fs.root(), e.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			if inode, ok := socketInode(target); ok {
+				if _, seen := owners[inode]; !seen {
+					owners[inode] = pid
+				}
+			}
+		}
+	}
+	return owners, nil
+}
+
+// socketInode extrait l'inode d'une cible de lien "socket:[12345]"
+func socketInode(target string) (uint64, bool) {
+	if !strings.HasPrefix(target, "socket:[") || !strings.HasSuffix(target, "]") {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(target[len("socket:["):len(target)-1], 10, 64)
+	return inode, err == nil
+}
+
+// parseSocketFile analyse un fichier /proc/net/<proto>
+func parseSocketFile(path, proto string) ([]Socket, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sockets []Socket
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // en-tête
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		localAddr, localPort, err := parseHexAddr(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", path, err)
+		}
+		remoteAddr, remotePort, err := parseHexAddr(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", path, err)
+		}
+		uid, _ := strconv.Atoi(fields[7])
+		inode, _ := strconv.ParseUint(fields[9], 10, 64)
+
+		state := tcpStates[fields[3]]
+		if strings.HasPrefix(proto, "udp") {
+			// Pour UDP, l'état 07 (CLOSE) désigne une socket liée non connectée
+			state = "UNCONN"
+			if fields[3] == "01" {
+				state = "ESTABLISHED"
+			}
+		}
+
+		sockets = append(sockets, Socket{
+			Proto:      proto,
+			LocalAddr:  localAddr,
+			LocalPort:  localPort,
+			RemoteAddr: remoteAddr,
+			RemotePort: remotePort,
+			State:      state,
+			UID:        uid,
+			Inode:      inode,
+		})
+	}
+	return sockets, scanner.Err()
+}
+
+// parseHexAddr décode "0100007F:1F90" en ("127.0.0.1", 8080). L'adresse est
+// stockée par mots de 32 bits dans l'ordre de l'hôte (petit-boutiste).
+func parseHexAddr(s string) (string, int, error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return "", 0, fmt.Errorf("adresse invalide : %s", s)
+	}
+	raw, err := hex.DecodeString(s[:i])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("adresse invalide : %s", s)
+	}
+	port, err := strconv.ParseUint(s[i+1:], 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("port invalide : %s", s)
+	}
+
+	ip := make(net.IP, len(raw))
+	for w := 0; w < len(raw); w += 4 {
+		ip[w], ip[w+1], ip[w+2], ip[w+3] = raw[w+3], raw[w+2], raw[w+1], raw[w]
+	}
+	return ip.String(), int(port), nil
+}
+
+// ListeningSockets liste les sockets en écoute du système
+func ListeningSockets() ([]Socket, error) {
+	return procFS().ListeningSockets()
+}
+
+// PortOwners retourne les sockets en écoute sur un port donné (TCP et UDP)
+func PortOwners(port int) ([]Socket, error) {
+	all, err := ListeningSockets()
+	if err != nil {
+		return nil, err
+	}
+	var matches []Socket
+	for _, s := range all {
+		if s.LocalPort == port {
+			matches = append(matches, s)
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,90 @@
+package procops
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestParseHexAddr(t *testing.T) {
+	tests := []struct {
+		name, in string
+		addr     string
+		port     int
+	}{
+		{"IPv4 boucle locale", "0100007F:1F90", "127.0.0.1", 8080},
+		{"IPv4 toutes interfaces", "00000000:0016", "0.0.0.0", 22},
+		{"IPv4 privée", "0A01A8C0:01BB", "192.168.1.10", 443},
+		{"IPv6 boucle locale", "00000000000000000000000001000000:0050", "::1", 80},
+		{"IPv6 lien local", "000080FE000000000000000001000000:0035", "fe80::1", 53},
+		{"IPv6 documentation", "B80D0120000000000000000001000000:1F90", "2001:db8::1", 8080},
+		{"IPv4 dans IPv6", "0000000000000000FFFF00000100007F:0050", "127.0.0.1", 80},
+	}
+	for _, tt := range tests {
+		addr, port, err := parseHexAddr(tt.in)
+		if err != nil {
+			t.Errorf("%s : %v", tt.name, err)
+			continue
+		}
+		if addr != tt.addr || port != tt.port {
+			t.Errorf("%s : parseHexAddr(%q) = %s:%d, attendu %s:%d", tt.name, tt.in, addr, port, tt.addr, tt.port)
+		}
+	}
+}
+
+func TestParseHexAddrInvalid(t *testing.T) {
+	for _, in := range []string{
+		"0100007F",       // sans port
+		"0100007G:0050",  // adresse non hexadécimale
+		"01007F:0050",    // ni IPv4 ni IPv6
+		"0100007F:10000", // port hors limites
+		"0100007F:",
+	} {
+		if addr, port, err := parseHexAddr(in); err == nil {
+			t.Errorf("parseHexAddr(%q) = %s:%d, erreur attendue", in, addr, port)
+		}
+	}
+}
+
+func TestProcFSSockets(t *testing.T) {
+	sockets, err := ProcFS{Root: fixtureRoot}.Sockets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// tcp (2) + tcp6 (1) + udp (1), udp6 absent
+	if len(sockets) != 4 {
+		t.Fatalf("%d sockets : %+v", len(sockets), sockets)
+	}
+	conn := sockets[1]
+	if conn.State != "ESTABLISHED" || conn.RemoteAddr != "127.0.0.1" || conn.RemotePort != 54321 || conn.UID != 1000 {
+		t.Errorf("connexion = %+v", conn)
+	}
+	if udp := sockets[3]; udp.Proto != "udp" || udp.State != "UNCONN" || udp.LocalPort != 68 {
+		t.Errorf("udp = %+v", udp)
+	}
+}
+
+func TestProcFSListeningSockets(t *testing.T) {
+	listening, err := ProcFS{Root: fixtureRoot}.ListeningSockets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ports []int
+	for _, s := range listening {
+		ports = append(ports, s.LocalPort)
+	}
+	if want := []int{68, 80, 8080}; !equalInts(ports, want) {
+		t.Fatalf("ports = %v, attendu %v", ports, want)
+	}
+	if six := listening[1]; six.Proto != "tcp6" || six.LocalAddr != "::1" || six.PID != 0 {
+		t.Errorf("tcp6 = %+v", six)
+	}
+
+	// Propriétaire trouvé par le lien testdata/proc/201/fd/3 -> socket:[40001]
+	if runtime.GOOS == "windows" {
+		t.Skip("liens symboliques non garantis")
+	}
+	web := listening[2]
+	if web.PID != 201 || web.Process == nil || web.Process.Name != "python3" {
+		t.Errorf("propriétaire de :8080 = %d %+v", web.PID, web.Process)
+	}
+}
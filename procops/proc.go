@@ -1,96 +1,249 @@
 package procops
 
 import (
-	"bytes"
-	"encoding/csv"
-	"os/exec"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-// ProcessInfo représente les informations de base d'un processus
+// ProcessInfo représente les informations d'un processus.
+// Les champs non fournis par le backend restent à leur valeur zéro (UID à -1).
 type ProcessInfo struct {
-	PID  string
-	Name string
+	PID        int           `json:"pid"`
+	PPID       int           `json:"ppid"`
+	PGID       int           `json:"pgid"` // groupe de processus (0 si inconnu)
+	SID        int           `json:"sid"`  // session (0 si inconnu)
+	UID        int           `json:"uid"`
+	User       string        `json:"user"`
+	Name       string        `json:"name"`
+	State      string        `json:"state"`       // R, S, D, Z, T...
+	RSS        uint64        `json:"rss_bytes"`   // mémoire résidente
+	VSize      uint64        `json:"vsize_bytes"` // mémoire virtuelle
+	CPUTime    time.Duration `json:"cpu_time_ns"` // temps CPU cumulé (user + sys)
+	CPUPercent float64       `json:"cpu_percent"` // %CPU sur le dernier intervalle (Sampler uniquement)
+	StartTime  time.Time     `json:"start_time"`
+	Cmdline    string        `json:"cmdline"`
+	Cgroup     string        `json:"cgroup,omitempty"`    // chemin cgroup (Linux, backend /proc)
+	Container  string        `json:"container,omitempty"` // identifiant court du conteneur
 }
 
-// ListProcesses liste les N premiers processus selon l'OS
-func ListProcesses(topN int) ([]ProcessInfo, error) {
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("tasklist", "/FO", "CSV", "/NH")
-	} else {
-		cmd = exec.Command("ps", "-Ao", "pid,comm", "--no-headers")
+// Backend fournit la liste des processus du système
+type Backend interface {
+	Name() string
+	Processes() ([]ProcessInfo, error)
+}
+
+// fallbackBackend essaie le backend principal puis le secours en cas d'échec
+type fallbackBackend struct {
+	primary, secondary Backend
+}
+
+func (b fallbackBackend) Name() string {
+	return b.primary.Name() + "+" + b.secondary.Name()
+}
+
+func (b fallbackBackend) Processes() ([]ProcessInfo, error) {
+	procs, err := b.primary.Processes()
+	if err == nil {
+		return procs, nil
 	}
+	return b.secondary.Processes()
+}
 
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
+// Process lit un processus avec le backend principal ; le secours n'est
+// essayé que si le principal a échoué pour une autre raison que l'absence du PID
+func (b fallbackBackend) Process(pid int) (ProcessInfo, error) {
+	p, err := lookupIn(b.primary, pid)
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		return p, err
 	}
+	return lookupIn(b.secondary, pid)
+}
 
-	var processes []ProcessInfo
-	if runtime.GOOS == "windows" {
-		reader := csv.NewReader(bytes.NewReader(output))
-		records, err := reader.ReadAll()
-		if err != nil {
-			return nil, err
+// processLookup est implémenté par les backends capables de lire un seul processus
+type processLookup interface {
+	Process(pid int) (ProcessInfo, error)
+}
+
+// lookupIn lit un processus : directement si le backend le permet, sinon
+// dans la liste complète. Un PID absent donne une erreur os.ErrNotExist.
+func lookupIn(b Backend, pid int) (ProcessInfo, error) {
+	if l, ok := b.(processLookup); ok {
+		return l.Process(pid)
+	}
+	procs, err := b.Processes()
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	for _, p := range procs {
+		if p.PID == pid {
+			return p, nil
 		}
-		for i, record := range records {
-			if i >= topN && topN > 0 {
-				break
-			}
-			processes = append(processes, ProcessInfo{
-				PID:  record[1],
-				Name: record[0],
-			})
+	}
+	return ProcessInfo{}, notFound(pid)
+}
+
+// notFound signale un PID absent de la liste des processus
+func notFound(pid int) error {
+	return fmt.Errorf("processus %d introuvable : %w", pid, os.ErrNotExist)
+}
+
+// Backend utilisé par ListProcesses
+var backend = NewDefaultBackend()
+
+// NewDefaultBackend choisit /proc sous Linux (ps en secours), ps ou tasklist ailleurs
+func NewDefaultBackend() Backend {
+	if runtime.GOOS == "linux" {
+		if _, err := os.Stat(filepath.Join(defaultProcRoot, "self", "stat")); err == nil {
+			return fallbackBackend{primary: ProcFS{Root: defaultProcRoot}, secondary: CommandBackend{}}
 		}
-	} else {
-		lines := strings.Split(string(output), "\n")
-		for i, line := range lines {
-			if line == "" {
-				continue
-			}
-			if i >= topN && topN > 0 {
-				break
-			}
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				processes = append(processes, ProcessInfo{
-					PID:  fields[0],
-					Name: fields[1],
-				})
-			}
+	}
+	return CommandBackend{}
+}
+
+// NewBackend retourne le backend demandé : "auto" (ou vide), "proc" ou "ps"
+func NewBackend(name string) (Backend, error) {
+	switch strings.ToLower(name) {
+	case "", "auto":
+		return NewDefaultBackend(), nil
+	case "proc", "procfs":
+		return ProcFS{Root: defaultProcRoot}, nil
+	case "ps", "command":
+		return CommandBackend{}, nil
+	default:
+		return nil, fmt.Errorf("backend processus inconnu : %s (auto, proc, ps)", name)
+	}
+}
+
+// SetBackend remplace le backend utilisé pour lister les processus
+func SetBackend(b Backend) {
+	backend = b
+}
+
+// procFS retourne le ProcFS du backend actif, ou /proc par défaut. Utilisé
+// par les fonctions propres à Linux (sockets, descripteurs...).
+func procFS() ProcFS {
+	switch b := backend.(type) {
+	case ProcFS:
+		return b
+	case fallbackBackend:
+		if fs, ok := b.primary.(ProcFS); ok {
+			return fs
 		}
 	}
+	return ProcFS{Root: defaultProcRoot}
+}
 
+// lookupProcess lit un seul processus avec le backend actif
+func lookupProcess(pid int) (ProcessInfo, error) {
+	return lookupIn(backend, pid)
+}
+
+// CurrentBackend retourne le backend actif
+func CurrentBackend() Backend {
+	return backend
+}
+
+// ListProcesses liste les processus triés selon opts et garde les N premiers
+func ListProcesses(opts ListOptions) ([]ProcessInfo, error) {
+	processes, err := backend.Processes()
+	if err != nil {
+		return nil, err
+	}
+	SortProcesses(processes, opts.SortBy, opts.Order)
+	if opts.TopN > 0 && len(processes) > opts.TopN {
+		processes = processes[:opts.TopN]
+	}
 	return processes, nil
 }
 
-// FilterProcesses recherche des processus par nom
-func FilterProcesses(keyword string) ([]ProcessInfo, error) {
-	all, err := ListProcesses(0)
+// Filter décrit une recherche de processus
+type Filter struct {
+	Pattern string // sous-chaîne (insensible à la casse) ou expression régulière
+	Regex   bool   // Pattern est une expression régulière
+	Cmdline bool   // chercher dans la ligne de commande complète plutôt que le nom
+	Cgroup  string // ne garder que ce cgroup et ses descendants (vide = tous)
+}
+
+// matcher compile le filtre en fonction de test d'un processus
+func (f Filter) matcher() (func(ProcessInfo) bool, error) {
+	var match func(string) bool
+	if f.Regex {
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("expression régulière invalide : %w", err)
+		}
+		match = re.MatchString
+	} else {
+		keyword := strings.ToLower(f.Pattern)
+		match = func(s string) bool { return strings.Contains(strings.ToLower(s), keyword) }
+	}
+	return func(p ProcessInfo) bool {
+		if f.Cgroup != "" && !InCgroup(p.Cgroup, f.Cgroup) {
+			return false
+		}
+		target := p.Name
+		if f.Cmdline && p.Cmdline != "" {
+			target = p.Cmdline
+		}
+		return match(target)
+	}, nil
+}
+
+// FindProcesses retourne les processus correspondant au filtre
+func FindProcesses(f Filter) ([]ProcessInfo, error) {
+	match, err := f.matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := ListProcesses(ListOptions{})
 	if err != nil {
 		return nil, err
 	}
 
 	var filtered []ProcessInfo
-	keyword = strings.ToLower(keyword)
 	for _, p := range all {
-		if strings.Contains(strings.ToLower(p.Name), keyword) {
+		if match(p) {
 			filtered = append(filtered, p)
 		}
 	}
 	return filtered, nil
 }
 
-// KillProcess termine un processus par son PID
-func KillProcess(pid string) error {
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("taskkill", "/PID", pid, "/T", "/F")
-	} else {
-		cmd = exec.Command("kill", "-9", pid)
-	}
+// FilterProcesses recherche des processus par nom
+func FilterProcesses(keyword string) ([]ProcessInfo, error) {
+	return FindProcesses(Filter{Pattern: keyword})
+}
+
+// Cache des noms d'utilisateurs par UID
+var (
+	userCacheMu sync.Mutex
+	userCache   = map[int]string{}
+)
 
-	return cmd.Run()
+// lookupUser retourne le nom associé à un UID (ou l'UID lui-même si inconnu)
+func lookupUser(uid int) string {
+	if uid < 0 {
+		return ""
+	}
+	userCacheMu.Lock()
+	defer userCacheMu.Unlock()
+	if name, ok := userCache[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	userCache[uid] = name
+	return name
 }
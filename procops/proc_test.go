@@ -0,0 +1,278 @@
+package procops
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Répertoire imitant /proc : voir testdata/proc
+const fixtureRoot = "testdata/proc"
+
+// Heure de démarrage du système déclarée dans testdata/proc/stat
+var fixtureBoot = time.Unix(1700000000, 0)
+
+// useFixture fait lire les processus dans testdata/proc le temps du test
+func useFixture(t *testing.T) {
+	t.Helper()
+	prev := CurrentBackend()
+	SetBackend(ProcFS{Root: fixtureRoot})
+	t.Cleanup(func() { SetBackend(prev) })
+}
+
+func pids(procs []ProcessInfo) []int {
+	out := make([]int, len(procs))
+	for i, p := range procs {
+		out[i] = p.PID
+	}
+	return out
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestProcFSProcesses(t *testing.T) {
+	procs, err := ProcFS{Root: fixtureRoot}.Processes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pids(procs), []int{1, 2, 100, 200, 201, 202, 300}; !equalInts(got, want) {
+		t.Fatalf("PIDs = %v, attendu %v", got, want)
+	}
+
+	var py ProcessInfo
+	for _, p := range procs {
+		if p.PID == 201 {
+			py = p
+		}
+	}
+	if py.Name != "python3" || py.State != "R" || py.PPID != 200 || py.PGID != 201 || py.SID != 200 {
+		t.Errorf("champs stat inattendus : %+v", py)
+	}
+	if py.UID != 1000 {
+		t.Errorf("UID = %d, attendu 1000", py.UID)
+	}
+	if want := "python3 /opt/app/worker.py --queue jobs"; py.Cmdline != want {
+		t.Errorf("Cmdline = %q, attendu %q", py.Cmdline, want)
+	}
+	if want := 100 * time.Second; py.CPUTime != want {
+		t.Errorf("CPUTime = %s, attendu %s", py.CPUTime, want)
+	}
+	if want := fixtureBoot.Add(200 * time.Second); !py.StartTime.Equal(want) {
+		t.Errorf("StartTime = %s, attendu %s", py.StartTime, want)
+	}
+	if want := uint64(250000 * os.Getpagesize()); py.RSS != want {
+		t.Errorf("RSS = %d, attendu %d", py.RSS, want)
+	}
+	if py.VSize != 900000000 {
+		t.Errorf("VSize = %d, attendu 900000000", py.VSize)
+	}
+}
+
+func TestProcFSNameWithParens(t *testing.T) {
+	p, err := ProcFS{Root: fixtureRoot}.Process(300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "my app (v2)" || p.State != "S" || p.PPID != 1 {
+		t.Errorf("nom avec parenthèses mal analysé : %+v", p)
+	}
+}
+
+func TestProcFSMissingRoot(t *testing.T) {
+	if _, err := (ProcFS{Root: "testdata/absent"}).Processes(); err == nil {
+		t.Fatal("erreur attendue pour une racine absente")
+	}
+}
+
+func TestParseStatInvalid(t *testing.T) {
+	for _, stat := range []string{"", "12 sans parenthèses S 1", "12 (court) S 1 2 3"} {
+		if _, err := parseStat(stat, fixtureBoot); err == nil {
+			t.Errorf("parseStat(%q) : erreur attendue", stat)
+		}
+	}
+}
+
+func TestListProcessesTopN(t *testing.T) {
+	useFixture(t)
+
+	procs, err := ListProcesses(ListOptions{TopN: 3, SortBy: SortRSS})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pids(procs), []int{201, 300, 1}; !equalInts(got, want) {
+		t.Errorf("top 3 RSS = %v, attendu %v", got, want)
+	}
+
+	all, err := ListProcesses(ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 7 {
+		t.Errorf("%d processus, attendu 7", len(all))
+	}
+}
+
+func TestFindProcesses(t *testing.T) {
+	useFixture(t)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int
+	}{
+		{"sous-chaîne", Filter{Pattern: "SSH"}, []int{100}},
+		{"nom et non ligne de commande", Filter{Pattern: "worker"}, []int{202}},
+		{"ligne de commande", Filter{Pattern: "worker", Cmdline: true}, []int{201, 202}},
+		{"regex", Filter{Pattern: "^(bash|sshd)$", Regex: true}, []int{100, 200}},
+		{"aucun résultat", Filter{Pattern: "nginx"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procs, err := FindProcesses(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pids(procs); !equalInts(got, tt.want) {
+				t.Errorf("PIDs = %v, attendu %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := FindProcesses(Filter{Pattern: "(", Regex: true}); err == nil ||
+		!strings.Contains(err.Error(), "expression régulière invalide") {
+		t.Errorf("erreur attendue pour une regex invalide, obtenu %v", err)
+	}
+}
+
+func TestSortProcesses(t *testing.T) {
+	base := []ProcessInfo{
+		{PID: 3, Name: "beta", RSS: 10, VSize: 300, CPUTime: 5 * time.Second, StartTime: fixtureBoot.Add(3 * time.Second)},
+		{PID: 1, Name: "Alpha", RSS: 30, VSize: 100, CPUTime: 5 * time.Second, StartTime: fixtureBoot.Add(1 * time.Second)},
+		{PID: 2, Name: "gamma", RSS: 20, VSize: 200, CPUTime: 9 * time.Second, StartTime: fixtureBoot.Add(2 * time.Second)},
+	}
+	tests := []struct {
+		key   SortKey
+		order string
+		want  []int
+	}{
+		{SortNone, "", []int{3, 1, 2}},
+		{SortPID, "", []int{1, 2, 3}},
+		{SortName, "", []int{1, 3, 2}},
+		{SortRSS, "", []int{1, 2, 3}},
+		{SortRSS, OrderAsc, []int{3, 2, 1}},
+		{SortMem, "", []int{3, 2, 1}},
+		{SortStart, "", []int{3, 2, 1}},
+		{SortCPU, "", []int{2, 1, 3}}, // égalité de CPU départagée par le PID
+		{SortPID, OrderDesc, []int{3, 2, 1}},
+	}
+	for _, tt := range tests {
+		procs := append([]ProcessInfo(nil), base...)
+		SortProcesses(procs, tt.key, tt.order)
+		if got := pids(procs); !equalInts(got, tt.want) {
+			t.Errorf("tri %q %q = %v, attendu %v", tt.key, tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestParseSortKeyAndOrder(t *testing.T) {
+	if key, err := ParseSortKey(" RSS "); err != nil || key != SortRSS {
+		t.Errorf("ParseSortKey(RSS) = %q, %v", key, err)
+	}
+	if _, err := ParseSortKey("taille"); err == nil {
+		t.Error("erreur attendue pour un critère inconnu")
+	}
+	if order, err := ParseOrder("DESC"); err != nil || order != OrderDesc {
+		t.Errorf("ParseOrder(DESC) = %q, %v", order, err)
+	}
+	if _, err := ParseOrder("haut"); err == nil {
+		t.Error("erreur attendue pour un ordre inconnu")
+	}
+}
+
+func TestLookupProcess(t *testing.T) {
+	// ProcFS lit directement /proc/<pid>
+	p, err := lookupIn(ProcFS{Root: fixtureRoot}, 201)
+	if err != nil || p.Name != "python3" {
+		t.Fatalf("lookup 201 = %+v, %v", p, err)
+	}
+	if _, err := lookupIn(ProcFS{Root: fixtureRoot}, 999); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("PID absent : %v", err)
+	}
+
+	// ps -p (ou tasklist filtré) sur le processus courant
+	tool := "ps"
+	if runtime.GOOS == "windows" {
+		tool = "tasklist"
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		t.Skip(tool + " indisponible")
+	}
+	self, err := CommandBackend{}.Process(os.Getpid())
+	if err != nil || self.PID != os.Getpid() || self.Name == "" {
+		t.Fatalf("processus courant = %+v, %v", self, err)
+	}
+	if _, err := (CommandBackend{}).Process(1 << 30); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("PID absent : %v", err)
+	}
+}
+
+func TestProcFSSkipsUnreadablePID(t *testing.T) {
+	root := t.TempDir()
+	copyFile := func(src, dst string) {
+		t.Helper()
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	copyFile(filepath.Join(fixtureRoot, "stat"), filepath.Join(root, "stat"))
+	for _, name := range []string{"stat", "status", "cmdline"} {
+		copyFile(filepath.Join(fixtureRoot, "201", name), filepath.Join(root, "201", name))
+	}
+	// 202 : stat illisible (répertoire) ; 203 : stat tronqué ; 204 : processus
+	// disparu dont il ne reste que le répertoire
+	if err := os.MkdirAll(filepath.Join(root, "202", "stat"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	copyFile(filepath.Join(fixtureRoot, "201", "status"), filepath.Join(root, "203", "status"))
+	if err := os.WriteFile(filepath.Join(root, "203", "stat"), []byte("203 (tronqué"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "204"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	procs, err := ProcFS{Root: root}.Processes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pids(procs); !equalInts(got, []int{201}) {
+		t.Errorf("PIDs = %v, attendu [201]", got)
+	}
+
+	// Seul un /proc illisible fait échouer la liste
+	if _, err := (ProcFS{Root: filepath.Join(root, "absent")}).Processes(); err == nil {
+		t.Error("erreur attendue pour une racine absente")
+	}
+}
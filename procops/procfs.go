@@ -0,0 +1,191 @@
+package procops
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Racine par défaut du pseudo-système de fichiers des processus (Linux)
+const defaultProcRoot = "/proc"
+
+// Tops d'horloge par seconde utilisés dans /proc/<pid>/stat (USER_HZ).
+// Vaut 100 sur toutes les architectures Linux courantes.
+const clockTicks = 100
+
+// ProcFS lit les processus directement dans /proc (Linux uniquement)
+type ProcFS struct {
+	Root string // répertoire racine, "/proc" par défaut
+}
+
+// Name retourne le nom du backend
+func (ProcFS) Name() string { return "procfs" }
+
+// root retourne le répertoire racine effectif
+func (fs ProcFS) root() string {
+	if fs.Root == "" {
+		return defaultProcRoot
+	}
+	return fs.Root
+}
+
+// Processes parcourt les répertoires numériques de /proc. Les processus
+// illisibles sont ignorés.
+func (fs ProcFS) Processes() ([]ProcessInfo, error) {
+	entries, err := os.ReadDir(fs.root())
+	if err != nil {
+		return nil, err
+	}
+	bootTime, err := fs.bootTime()
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, e := range entries {
+		if pid, err := strconv.Atoi(e.Name()); err == nil && e.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+
+	var processes []ProcessInfo
+	for _, pid := range pids {
+		p, err := fs.readProcess(pid, bootTime)
+		if err != nil {
+			// Un processus terminé pendant le parcours (ENOENT, ESRCH) ou illisible
+			// (EACCES) est ignoré : seul un /proc illisible fait échouer la liste
+			continue
+		}
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
+
+// Process lit les informations d'un seul processus
+func (fs ProcFS) Process(pid int) (ProcessInfo, error) {
+	bootTime, err := fs.bootTime()
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	return fs.readProcess(pid, bootTime)
+}
+
+// readProcess assemble stat, status et cmdline d'un processus
+func (fs ProcFS) readProcess(pid int, bootTime time.Time) (ProcessInfo, error) {
+	dir := filepath.Join(fs.root(), strconv.Itoa(pid))
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	p, err := parseStat(string(data), bootTime)
+	if err != nil {
+		return ProcessInfo{}, fmt.Errorf("%s/stat : %w", dir, err)
+	}
+	p.PID = pid
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	p.UID = parseStatusUID(status)
+	p.User = lookupUser(p.UID)
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return ProcessInfo{}, err
+	}
+	p.Cmdline = strings.Join(strings.Split(string(bytes.TrimRight(cmdline, "\x00")), "\x00"), " ")
+
+	// Absent sur les noyaux sans cgroups : le processus n'est simplement pas annoté
+	if cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup")); err == nil {
+		p.Cgroup = parseCgroup(cgroup)
+		p.Container = ContainerID(p.Cgroup)
+	}
+
+	return p, nil
+}
+
+// bootTime lit l'heure de démarrage du système (ligne btime de /proc/stat)
+func (fs ProcFS) bootTime() (time.Time, error) {
+	f, err := os.Open(filepath.Join(fs.root(), "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "btime" {
+			sec, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(sec, 0), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return time.Time{}, fmt.Errorf("btime absent de %s/stat", fs.root())
+}
+
+// parseStat analyse /proc/<pid>/stat. Le nom est entre parenthèses et peut
+// contenir des espaces ou des parenthèses : on coupe sur la dernière ')'.
+func parseStat(stat string, bootTime time.Time) (ProcessInfo, error) {
+	open := strings.IndexByte(stat, '(')
+	closing := strings.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return ProcessInfo{}, fmt.Errorf("format stat invalide")
+	}
+	// fields[0] correspond au champ 3 (state) de proc(5)
+	fields := strings.Fields(stat[closing+1:])
+	if len(fields) < 22 {
+		return ProcessInfo{}, fmt.Errorf("format stat invalide : %d champs", len(fields))
+	}
+
+	num := func(i int) uint64 {
+		v, _ := strconv.ParseUint(fields[i], 10, 64)
+		return v
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	pgid, _ := strconv.Atoi(fields[2])
+	sid, _ := strconv.Atoi(fields[3])
+	ticks := num(11) + num(12) // utime + stime
+
+	return ProcessInfo{
+		Name:      stat[open+1 : closing],
+		State:     fields[0],
+		PPID:      ppid,
+		PGID:      pgid,
+		SID:       sid,
+		CPUTime:   time.Duration(ticks) * time.Second / clockTicks,
+		StartTime: bootTime.Add(time.Duration(num(19)) * time.Second / clockTicks),
+		VSize:     num(20),
+		RSS:       num(21) * uint64(os.Getpagesize()),
+	}, nil
+}
+
+// parseStatusUID extrait l'UID réel de la ligne "Uid:" de /proc/<pid>/status
+func parseStatusUID(status []byte) int {
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "Uid:") {
+			fields := strings.Fields(line[len("Uid:"):])
+			if len(fields) > 0 {
+				if uid, err := strconv.Atoi(fields[0]); err == nil {
+					return uid
+				}
+			}
+		}
+	}
+	return -1
+}
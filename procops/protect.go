@@ -0,0 +1,97 @@
+package procops
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Protection définit les processus qu'on refuse de tuer. Le PID 1 et l'outil
+// lui-même sont toujours protégés, même avec une politique vide.
+type Protection struct {
+	PIDs     []int    `json:"pids"`
+	Names    []string `json:"names"`     // nom exact, insensible à la casse (ex: sshd)
+	Users    []string `json:"users"`     // propriétaires (ex: root)
+	BelowPID int      `json:"below_pid"` // protège tous les PID strictement inférieurs
+}
+
+// ProtectedError est retournée quand la cible d'un signal est protégée
+type ProtectedError struct {
+	PID    int
+	Name   string
+	Reason string
+}
+
+func (e *ProtectedError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("processus %d (%s) protégé : %s", e.PID, e.Name, e.Reason)
+	}
+	return fmt.Sprintf("processus %d protégé : %s", e.PID, e.Reason)
+}
+
+// Politique appliquée par SendSignal
+var protection Protection
+
+// SetProtection remplace la politique de protection
+func SetProtection(p Protection) {
+	protection = p
+}
+
+// CheckProtected vérifie un processus contre la politique active
+func CheckProtected(p ProcessInfo) error {
+	return protection.Check(p)
+}
+
+// Check retourne une *ProtectedError si le processus est protégé
+func (pol Protection) Check(p ProcessInfo) error {
+	deny := func(reason string) error {
+		return &ProtectedError{PID: p.PID, Name: p.Name, Reason: reason}
+	}
+
+	switch {
+	case p.PID == 1:
+		return deny("processus init")
+	case p.PID == os.Getpid():
+		return deny("processus de l'outil")
+	case pol.BelowPID > 0 && p.PID < pol.BelowPID:
+		return deny(fmt.Sprintf("PID inférieur à %d", pol.BelowPID))
+	}
+	for _, pid := range pol.PIDs {
+		if p.PID == pid {
+			return deny("PID dans la liste protégée")
+		}
+	}
+	for _, name := range pol.Names {
+		if p.Name != "" && strings.EqualFold(p.Name, name) {
+			return deny("nom '" + name + "' dans la liste protégée")
+		}
+	}
+	for _, user := range pol.Users {
+		if p.User != "" && p.User == user {
+			return deny("utilisateur '" + user + "' protégé")
+		}
+	}
+	return nil
+}
+
+// checkPID applique la politique à un PID en lisant ce seul processus. Si son
+// nom ou son propriétaire ne peut être lu alors que des règles names ou users
+// sont définies, le signal est refusé plutôt qu'envoyé sans vérification.
+func checkPID(pid int) error {
+	if err := protection.Check(ProcessInfo{PID: pid}); err != nil {
+		return err
+	}
+	target, err := lookupProcess(pid)
+	needName, needUser := len(protection.Names) > 0, len(protection.Users) > 0
+	switch {
+	case err != nil && (needName || needUser):
+		return fmt.Errorf("protection du processus %d invérifiable : %w", pid, err)
+	case err != nil:
+		return nil
+	case needName && target.Name == "":
+		return &ProtectedError{PID: pid, Reason: "nom inconnu, règles names non vérifiables"}
+	case needUser && target.User == "":
+		return &ProtectedError{PID: pid, Name: target.Name, Reason: "propriétaire inconnu, règles users non vérifiables"}
+	}
+	return protection.Check(target)
+}
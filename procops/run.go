@@ -0,0 +1,82 @@
+package procops
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// RunOptions paramètre l'exécution d'une commande par Run
+type RunOptions struct {
+	Timeout time.Duration // 0 = pas de limite (hors annulation du contexte)
+	Dir     string
+	Env     []string // KEY=VALUE, ajoutés à l'environnement courant
+	Stdin   io.Reader
+	Stdout  io.Writer // nil = sortie ignorée
+	Stderr  io.Writer
+}
+
+// RunResult décrit la fin d'une commande et ce qu'elle a coûté
+type RunResult struct {
+	Command  string        `json:"command"`
+	Args     []string      `json:"args"`
+	PID      int           `json:"pid"`
+	ExitCode int           `json:"exit_code"` // -1 si tuée par un signal
+	TimedOut bool          `json:"timed_out"`
+	Wall     time.Duration `json:"wall_ns"`
+	User     time.Duration `json:"user_cpu_ns"`
+	System   time.Duration `json:"sys_cpu_ns"`
+	MaxRSS   uint64        `json:"max_rss_bytes"` // 0 si non disponible (Windows)
+}
+
+// Délai laissé aux descendants pour fermer les sorties après la fin de la commande
+const runWaitDelay = 2 * time.Second
+
+// Run exécute une commande et attend sa fin. À l'expiration du délai (ou à
+// l'annulation de ctx), tout le groupe de processus est tué, descendants compris.
+// Une sortie non nulle n'est pas une erreur : seul un échec de lancement en est une.
+func Run(ctx context.Context, opts RunOptions, name string, args ...string) (RunResult, error) {
+	result := RunResult{Command: name, Args: args, ExitCode: -1}
+	if args == nil {
+		result.Args = []string{}
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(os.Environ(), opts.Env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = opts.Stdin, opts.Stdout, opts.Stderr
+	cmd.WaitDelay = runWaitDelay
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd.Process.Pid) }
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return result, err
+	}
+	result.PID = cmd.Process.Pid
+
+	err := cmd.Wait()
+	result.Wall = time.Since(start)
+	result.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		return result, err
+	}
+	if state := cmd.ProcessState; state != nil {
+		result.ExitCode = state.ExitCode()
+		result.User = state.UserTime()
+		result.System = state.SystemTime()
+		result.MaxRSS = maxRSS(state)
+	}
+	return result, nil
+}
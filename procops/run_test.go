@@ -0,0 +1,98 @@
+package procops
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// skipWithoutShell écarte les tests qui lancent sh
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("sh et groupes de processus requis")
+	}
+}
+
+func TestRunExitCode(t *testing.T) {
+	skipWithoutShell(t)
+	var out bytes.Buffer
+	opts := RunOptions{Env: []string{"RUN_TEST=bonjour"}, Stdout: &out}
+	result, err := Run(context.Background(), opts, "sh", "-c", `echo "$RUN_TEST"; exit 7`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 7 || result.TimedOut || result.PID <= 0 {
+		t.Errorf("résultat = %+v", result)
+	}
+	if out.String() != "bonjour\n" {
+		t.Errorf("sortie = %q", out.String())
+	}
+}
+
+func TestRunStartError(t *testing.T) {
+	result, err := Run(context.Background(), RunOptions{}, "commande-inexistante-procops")
+	if err == nil {
+		t.Fatalf("erreur de lancement attendue : %+v", result)
+	}
+	if result.ExitCode != -1 || len(result.Args) != 0 || result.Args == nil {
+		t.Errorf("résultat = %+v", result)
+	}
+}
+
+func TestRunTimeoutKillsGroup(t *testing.T) {
+	skipWithoutShell(t)
+	// Le descendant garde la sortie ouverte : s'il survivait au délai, Wait
+	// attendrait runWaitDelay
+	var out bytes.Buffer
+	opts := RunOptions{Timeout: 200 * time.Millisecond, Stdout: &out}
+	result, err := Run(context.Background(), opts, "sh", "-c", "sleep 30 & echo $!; wait")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TimedOut || result.ExitCode != -1 {
+		t.Errorf("résultat = %+v", result)
+	}
+	if result.Wall >= runWaitDelay {
+		t.Errorf("durée %s : le descendant a survécu au délai", result.Wall)
+	}
+
+	child, err := strconv.Atoi(strings.TrimSpace(out.String()))
+	if err != nil {
+		t.Fatalf("PID du descendant illisible : %q", out.String())
+	}
+	// Sans /proc, la durée mesurée suffit ; un zombie non réclamé est bien mort.
+	// Le descendant a fermé sa sortie mais peut être encore en train de se terminer.
+	state := ""
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		data, err := os.ReadFile("/proc/" + strconv.Itoa(child) + "/stat")
+		if err != nil {
+			return
+		}
+		p, err := parseStat(string(data), time.Time{})
+		if err != nil || p.State == "Z" {
+			return
+		}
+		state = p.State
+	}
+	t.Errorf("descendant %d toujours vivant (état %s)", child, state)
+}
+
+func TestRunContextCancel(t *testing.T) {
+	skipWithoutShell(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	result, err := Run(ctx, RunOptions{}, "sleep", "30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Une annulation n'est pas un dépassement de délai
+	if result.TimedOut || result.ExitCode != -1 || result.Wall >= runWaitDelay {
+		t.Errorf("résultat = %+v", result)
+	}
+}
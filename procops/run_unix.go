@@ -0,0 +1,33 @@
+//go:build !windows
+
+package procops
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// setProcessGroup place la commande dans son propre groupe de processus
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup tue le groupe entier (PID négatif)
+func killProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// maxRSS lit le pic de mémoire résidente dans rusage : en Kio sous Linux,
+// en octets sous macOS
+func maxRSS(state *os.ProcessState) uint64 {
+	ru, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || ru.Maxrss <= 0 {
+		return 0
+	}
+	if runtime.GOOS == "darwin" {
+		return uint64(ru.Maxrss)
+	}
+	return uint64(ru.Maxrss) * 1024
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package procops
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// Windows n'a pas de groupes de processus au sens POSIX : l'arbre est tué
+// par taskkill /T
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func killProcessGroup(pid int) error {
+	return exec.Command("taskkill", "/PID", strconv.Itoa(pid), "/T", "/F").Run()
+}
+
+// Le pic de mémoire n'est pas exposé par ProcessState sous Windows
+func maxRSS(state *os.ProcessState) uint64 {
+	return 0
+}
@@ -0,0 +1,81 @@
+package procops
+
+import "time"
+
+// sampleKey identifie un processus entre deux échantillons : l'heure de
+// démarrage évite de confondre deux processus ayant reçu le même PID
+type sampleKey struct {
+	pid   int
+	start time.Time
+}
+
+// sample est l'état d'un processus retenu pour l'échantillon suivant
+type sample struct {
+	start time.Time
+	cpu   time.Duration
+}
+
+// Sampler calcule le %CPU de chaque processus à partir de la différence de
+// temps CPU entre deux échantillons successifs
+type Sampler struct {
+	prev     map[int]sample
+	prevTime time.Time
+}
+
+// NewSampler crée un échantillonneur vide
+func NewSampler() *Sampler {
+	return &Sampler{prev: map[int]sample{}}
+}
+
+// Sample relit les processus et renseigne CPUPercent. Au premier appel (ou pour
+// un processus apparu depuis), la moyenne depuis le démarrage est utilisée.
+// L'heure de démarrage d'un processus déjà vu est celle du premier échantillon :
+// déduite du temps écoulé (backend ps), elle varie d'une seconde d'un relevé à l'autre.
+func (s *Sampler) Sample(opts ListOptions) ([]ProcessInfo, error) {
+	processes, err := backend.Processes()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	elapsed := now.Sub(s.prevTime)
+
+	current := make(map[int]sample, len(processes))
+	for i := range processes {
+		p := &processes[i]
+		prev, seen := s.prev[p.PID]
+		if seen && sameStart(prev.start, p.StartTime) {
+			p.StartTime = prev.start
+		} else {
+			seen = false
+		}
+		current[p.PID] = sample{start: p.StartTime, cpu: p.CPUTime}
+
+		if seen && elapsed > 0 {
+			p.CPUPercent = percent(p.CPUTime-prev.cpu, elapsed)
+		} else if !p.StartTime.IsZero() {
+			p.CPUPercent = percent(p.CPUTime, now.Sub(p.StartTime))
+		}
+	}
+	s.prev, s.prevTime = current, now
+
+	SortProcesses(processes, opts.SortBy, opts.Order)
+	if opts.TopN > 0 && len(processes) > opts.TopN {
+		processes = processes[:opts.TopN]
+	}
+	return processes, nil
+}
+
+// sameStart compare deux heures de démarrage d'un même PID. Le backend ps
+// déduit le démarrage du temps écoulé : une seconde d'écart est tolérée.
+func sameStart(a, b time.Time) bool {
+	gap := a.Sub(b)
+	return gap <= time.Second && gap >= -time.Second
+}
+
+// percent retourne cpu/wall en pourcentage (peut dépasser 100 sur plusieurs cœurs)
+func percent(cpu, wall time.Duration) float64 {
+	if wall <= 0 || cpu < 0 {
+		return 0
+	}
+	return float64(cpu) / float64(wall) * 100
+}
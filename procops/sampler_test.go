@@ -0,0 +1,67 @@
+package procops
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeBackend retourne les processus préparés par le test
+type fakeBackend struct {
+	procs []ProcessInfo
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+func (b *fakeBackend) Processes() ([]ProcessInfo, error) {
+	return append([]ProcessInfo(nil), b.procs...), nil
+}
+
+func useFakeBackend(t *testing.T) *fakeBackend {
+	t.Helper()
+	fake := &fakeBackend{}
+	prev := CurrentBackend()
+	SetBackend(fake)
+	t.Cleanup(func() { SetBackend(prev) })
+	return fake
+}
+
+func TestSamplerJitteredStart(t *testing.T) {
+	fake := useFakeBackend(t)
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name     string
+		start    time.Time // heure de démarrage du second relevé
+		sameProc bool
+	}{
+		{"même seconde", start, true},
+		{"une seconde plus tard (ps)", start.Add(time.Second), true},
+		{"une seconde plus tôt (ps)", start.Add(-time.Second), true},
+		{"PID réattribué", start.Add(30 * time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSampler()
+			fake.procs = []ProcessInfo{{PID: 42, Name: "calc", StartTime: start, CPUTime: time.Second}}
+			if _, err := s.Sample(ListOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(100 * time.Millisecond)
+			// 50 ms de CPU sur ~100 ms : ~50 %, contre ~0,03 % en moyenne depuis le démarrage
+			fake.procs = []ProcessInfo{{PID: 42, Name: "calc", StartTime: tt.start, CPUTime: time.Second + 50*time.Millisecond}}
+			procs, err := s.Sample(ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := procs[0]
+			if tt.sameProc {
+				if p.CPUPercent < 10 {
+					t.Errorf("%%CPU = %.2f, moyenne depuis le démarrage au lieu de l'intervalle", p.CPUPercent)
+				}
+				if !p.StartTime.Equal(start) {
+					t.Errorf("heure de démarrage non stabilisée : %v, attendu %v", p.StartTime, start)
+				}
+			} else if p.CPUPercent > 1 {
+				t.Errorf("%%CPU = %.2f : processus réattribué confondu avec le précédent", p.CPUPercent)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package procops
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Intervalle de vérification de la fin d'un processus
+const exitPollInterval = 100 * time.Millisecond
+
+// Délai d'attente après SIGKILL avant de déclarer l'échec
+const killWait = 2 * time.Second
+
+// KillResult décrit l'issue d'un arrêt avec escalade
+type KillResult struct {
+	PID       int           `json:"pid"`
+	Signal    string        `json:"signal"`     // signal qui a terminé le processus
+	Escalated bool          `json:"escalated"`  // KILL envoyé après le délai de grâce
+	Elapsed   time.Duration `json:"elapsed_ns"` // durée entre le premier signal et la fin
+}
+
+// Signaler envoie les signaux et teste l'existence des processus.
+// Remplaçable (voir SetSignaler) pour exercer les chemins de kill sans
+// toucher aux vrais processus.
+type Signaler interface {
+	Signal(pid int, sig syscall.Signal) error
+	Alive(pid int) bool
+}
+
+// systemSignaler utilise les appels système de la plateforme
+type systemSignaler struct{}
+
+func (systemSignaler) Signal(pid int, sig syscall.Signal) error { return sendSignal(pid, sig) }
+func (systemSignaler) Alive(pid int) bool                       { return processAlive(pid) }
+
+// Signaler utilisé par SendSignal et KillGraceful
+var signaler Signaler = systemSignaler{}
+
+// SetSignaler remplace le signaler ; nil rétablit celui du système
+func SetSignaler(s Signaler) {
+	if s == nil {
+		s = systemSignaler{}
+	}
+	signaler = s
+}
+
+// ParseSignal accepte un nom (TERM, SIGTERM, term) ou un numéro (15)
+func ParseSignal(name string) (syscall.Signal, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if n, err := strconv.Atoi(name); err == nil {
+		for _, sig := range signalNames {
+			if int(sig) == n {
+				return sig, nil
+			}
+		}
+		return 0, fmt.Errorf("signal %d non supporté", n)
+	}
+	if sig, ok := signalNames[strings.TrimPrefix(name, "SIG")]; ok {
+		return sig, nil
+	}
+	return 0, fmt.Errorf("signal inconnu : %s (%s)", name, strings.Join(SignalNames(), ", "))
+}
+
+// SignalName retourne le nom court d'un signal (TERM, KILL...)
+func SignalName(sig syscall.Signal) string {
+	for name, s := range signalNames {
+		if s == sig {
+			return name
+		}
+	}
+	return strconv.Itoa(int(sig))
+}
+
+// SignalNames liste les signaux supportés sur cette plateforme
+func SignalNames() []string {
+	names := make([]string, 0, len(signalNames))
+	for name := range signalNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// KillProcess termine immédiatement un processus (SIGKILL)
+func KillProcess(pid int) error {
+	return SendSignal(pid, syscall.SIGKILL)
+}
+
+// SendSignal envoie un signal à un processus, sauf s'il est protégé
+// (voir SetProtection) : une *ProtectedError est alors retournée
+func SendSignal(pid int, sig syscall.Signal) error {
+	if pid <= 0 {
+		return fmt.Errorf("PID invalide : %d", pid)
+	}
+	if err := checkPID(pid); err != nil {
+		return err
+	}
+	return signaler.Signal(pid, sig)
+}
+
+// KillGraceful envoie TERM, attend jusqu'à grace que le processus se termine,
+// puis envoie KILL. Le résultat indique l'étape qui a mis fin au processus.
+func KillGraceful(pid int, grace time.Duration) (KillResult, error) {
+	result := KillResult{PID: pid, Signal: SignalName(syscall.SIGTERM)}
+	start := time.Now()
+
+	if err := SendSignal(pid, syscall.SIGTERM); err != nil {
+		return result, err
+	}
+	if waitExit(pid, grace) {
+		result.Elapsed = time.Since(start)
+		return result, nil
+	}
+
+	result.Signal, result.Escalated = SignalName(syscall.SIGKILL), true
+	if err := SendSignal(pid, syscall.SIGKILL); err != nil {
+		// Le processus a pu se terminer juste avant l'envoi de KILL
+		if !signaler.Alive(pid) {
+			result.Signal, result.Escalated = SignalName(syscall.SIGTERM), false
+			result.Elapsed = time.Since(start)
+			return result, nil
+		}
+		return result, err
+	}
+	if waitExit(pid, killWait) {
+		result.Elapsed = time.Since(start)
+		return result, nil
+	}
+	return result, fmt.Errorf("le processus %d ne s'est pas terminé après KILL", pid)
+}
+
+// waitExit vérifie régulièrement si le processus a disparu, au plus timeout
+func waitExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if !signaler.Alive(pid) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(exitPollInterval)
+	}
+}
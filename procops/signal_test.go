@@ -0,0 +1,272 @@
+package procops
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeSignaler enregistre les signaux et simule la fin des processus
+type fakeSignaler struct {
+	sent     []sentSignal
+	alive    map[int]bool
+	ignore   map[syscall.Signal]bool // signaux sans effet (processus récalcitrant)
+	undying  bool                    // le processus survit même à KILL
+	failWith error
+}
+
+type sentSignal struct {
+	pid int
+	sig syscall.Signal
+}
+
+func newFakeSignaler(pids ...int) *fakeSignaler {
+	f := &fakeSignaler{alive: map[int]bool{}, ignore: map[syscall.Signal]bool{}}
+	for _, pid := range pids {
+		f.alive[pid] = true
+	}
+	return f
+}
+
+func (f *fakeSignaler) Signal(pid int, sig syscall.Signal) error {
+	if f.failWith != nil {
+		return f.failWith
+	}
+	if !f.alive[pid] {
+		return syscall.ESRCH
+	}
+	f.sent = append(f.sent, sentSignal{pid, sig})
+	if !f.ignore[sig] && !f.undying {
+		delete(f.alive, pid)
+	}
+	return nil
+}
+
+func (f *fakeSignaler) Alive(pid int) bool { return f.alive[pid] }
+
+// useFakeSignaler installe le signaler le temps du test, avec les processus
+// de testdata/proc et une politique de protection vide
+func useFakeSignaler(t *testing.T, pids ...int) *fakeSignaler {
+	t.Helper()
+	useFixture(t)
+	f := newFakeSignaler(pids...)
+	SetSignaler(f)
+	SetProtection(Protection{})
+	t.Cleanup(func() {
+		SetSignaler(nil)
+		SetProtection(Protection{})
+	})
+	return f
+}
+
+func TestSendSignal(t *testing.T) {
+	f := useFakeSignaler(t, 201)
+
+	if err := SendSignal(201, syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.sent) != 1 || f.sent[0] != (sentSignal{201, syscall.SIGTERM}) {
+		t.Errorf("signaux envoyés = %v", f.sent)
+	}
+	if err := SendSignal(0, syscall.SIGTERM); err == nil {
+		t.Error("erreur attendue pour le PID 0")
+	}
+	if err := SendSignal(201, syscall.SIGTERM); !errors.Is(err, syscall.ESRCH) {
+		t.Errorf("erreur ESRCH attendue pour un processus terminé, obtenu %v", err)
+	}
+}
+
+func TestSendSignalProtected(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Protection
+		pid    int
+	}{
+		{"init", Protection{}, 1},
+		{"outil", Protection{}, os.Getpid()},
+		{"PID listé", Protection{PIDs: []int{201}}, 201},
+		{"nom", Protection{Names: []string{"SSHD"}}, 100},
+		{"utilisateur", Protection{Users: []string{"root"}}, 2},
+		{"PID bas", Protection{BelowPID: 150}, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := useFakeSignaler(t, tt.pid)
+			SetProtection(tt.policy)
+
+			err := SendSignal(tt.pid, syscall.SIGKILL)
+			var protected *ProtectedError
+			if !errors.As(err, &protected) || protected.PID != tt.pid {
+				t.Fatalf("ProtectedError attendue pour %d, obtenu %v", tt.pid, err)
+			}
+			if len(f.sent) != 0 {
+				t.Errorf("aucun signal ne devait partir : %v", f.sent)
+			}
+		})
+	}
+}
+
+// lookupBackend ne lit qu'un processus à la fois, ou échoue avec err
+type lookupBackend struct {
+	procs map[int]ProcessInfo
+	err   error
+	lists int // appels à Processes
+}
+
+func (b *lookupBackend) Name() string { return "lookup" }
+func (b *lookupBackend) Processes() ([]ProcessInfo, error) {
+	b.lists++
+	return nil, errors.New("liste complète interdite")
+}
+func (b *lookupBackend) Process(pid int) (ProcessInfo, error) {
+	if b.err != nil {
+		return ProcessInfo{}, b.err
+	}
+	if p, ok := b.procs[pid]; ok {
+		return p, nil
+	}
+	return ProcessInfo{}, notFound(pid)
+}
+
+func TestSendSignalLooksUpOnePID(t *testing.T) {
+	f := useFakeSignaler(t, 500, 501)
+	b := &lookupBackend{procs: map[int]ProcessInfo{
+		500: {PID: 500, Name: "sshd", User: "root"},
+		501: {PID: 501, Name: "worker", User: "app"},
+	}}
+	SetBackend(b)
+	SetProtection(Protection{Names: []string{"sshd"}, Users: []string{"postgres"}})
+
+	var protected *ProtectedError
+	if err := SendSignal(500, syscall.SIGTERM); !errors.As(err, &protected) {
+		t.Errorf("ProtectedError attendue pour sshd, obtenu %v", err)
+	}
+	if err := SendSignal(501, syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	if b.lists != 0 {
+		t.Errorf("%d listes complètes pour deux signaux", b.lists)
+	}
+	if len(f.sent) != 1 || f.sent[0].pid != 501 {
+		t.Errorf("signaux envoyés = %v", f.sent)
+	}
+}
+
+func TestSendSignalFailsClosed(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Protection
+		backend *lookupBackend
+		sent    bool
+	}{
+		{"lecture impossible, règle names", Protection{Names: []string{"sshd"}},
+			&lookupBackend{err: errors.New("ps indisponible")}, false},
+		{"lecture impossible, règle users", Protection{Users: []string{"root"}},
+			&lookupBackend{err: os.ErrPermission}, false},
+		{"nom inconnu", Protection{Names: []string{"sshd"}},
+			&lookupBackend{procs: map[int]ProcessInfo{500: {PID: 500, User: "app"}}}, false},
+		{"propriétaire inconnu", Protection{Users: []string{"root"}},
+			&lookupBackend{procs: map[int]ProcessInfo{500: {PID: 500, Name: "worker"}}}, false},
+		{"règles PID seules", Protection{PIDs: []int{42}},
+			&lookupBackend{err: errors.New("ps indisponible")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := useFakeSignaler(t, 500)
+			SetBackend(tt.backend)
+			SetProtection(tt.policy)
+
+			err := SendSignal(500, syscall.SIGTERM)
+			if sent := len(f.sent) == 1; sent != tt.sent {
+				t.Fatalf("signal envoyé = %v, attendu %v (erreur %v)", sent, tt.sent, err)
+			}
+			if !tt.sent && err == nil {
+				t.Error("refus attendu")
+			}
+		})
+	}
+}
+
+func TestKillProcess(t *testing.T) {
+	f := useFakeSignaler(t, 300)
+	if err := KillProcess(300); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.sent) != 1 || f.sent[0].sig != syscall.SIGKILL {
+		t.Errorf("signaux envoyés = %v, attendu KILL", f.sent)
+	}
+}
+
+func TestKillGracefulTerm(t *testing.T) {
+	f := useFakeSignaler(t, 201)
+
+	result, err := KillGraceful(201, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Escalated || result.Signal != "TERM" {
+		t.Errorf("résultat = %+v, attendu fin par TERM", result)
+	}
+	if len(f.sent) != 1 {
+		t.Errorf("signaux envoyés = %v, attendu TERM seul", f.sent)
+	}
+}
+
+func TestKillGracefulEscalates(t *testing.T) {
+	f := useFakeSignaler(t, 201)
+	f.ignore[syscall.SIGTERM] = true
+
+	result, err := KillGraceful(201, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Escalated || result.Signal != "KILL" {
+		t.Errorf("résultat = %+v, attendu escalade vers KILL", result)
+	}
+	want := []sentSignal{{201, syscall.SIGTERM}, {201, syscall.SIGKILL}}
+	if len(f.sent) != 2 || f.sent[0] != want[0] || f.sent[1] != want[1] {
+		t.Errorf("signaux envoyés = %v, attendu %v", f.sent, want)
+	}
+}
+
+func TestKillGracefulFailures(t *testing.T) {
+	f := useFakeSignaler(t, 201)
+	f.failWith = syscall.EPERM
+	if _, err := KillGraceful(201, 50*time.Millisecond); !errors.Is(err, syscall.EPERM) {
+		t.Errorf("EPERM attendue, obtenu %v", err)
+	}
+
+	var protected *ProtectedError
+	if _, err := KillGraceful(1, 50*time.Millisecond); !errors.As(err, &protected) {
+		t.Errorf("ProtectedError attendue pour init, obtenu %v", err)
+	}
+}
+
+func TestKillGracefulUndying(t *testing.T) {
+	if testing.Short() {
+		t.Skip("attend le délai après KILL")
+	}
+	f := useFakeSignaler(t, 201)
+	f.undying = true
+	if _, err := KillGraceful(201, 50*time.Millisecond); err == nil {
+		t.Error("erreur attendue pour un processus qui survit à KILL")
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for _, name := range []string{"TERM", "sigterm", " term ", "15"} {
+		if sig, err := ParseSignal(name); err != nil || sig != syscall.SIGTERM {
+			t.Errorf("ParseSignal(%q) = %v, %v", name, sig, err)
+		}
+	}
+	for _, name := range []string{"BOGUS", "999"} {
+		if _, err := ParseSignal(name); err == nil {
+			t.Errorf("ParseSignal(%q) : erreur attendue", name)
+		}
+	}
+	if got := SignalName(syscall.SIGKILL); got != "KILL" {
+		t.Errorf("SignalName(SIGKILL) = %q", got)
+	}
+}
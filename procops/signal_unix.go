@@ -0,0 +1,39 @@
+//go:build !windows
+
+package procops
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Signaux proposés à l'utilisateur
+var signalNames = map[string]syscall.Signal{
+	"HUP":  syscall.SIGHUP,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"TERM": syscall.SIGTERM,
+	"CONT": syscall.SIGCONT,
+	"STOP": syscall.SIGSTOP,
+	"CHLD": syscall.SIGCHLD,
+}
+
+func sendSignal(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
+
+// processAlive teste l'existence du processus (signal 0). Un zombie n'exécute
+// plus rien : il est considéré comme terminé même s'il n'a pas été récolté.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	if err != nil && !errors.Is(err, syscall.EPERM) {
+		return false
+	}
+	if p, err := procFS().Process(pid); err == nil && p.State == "Z" {
+		return false
+	}
+	return true
+}
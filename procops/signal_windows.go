@@ -0,0 +1,34 @@
+//go:build windows
+
+package procops
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Windows n'a pas de signaux : TERM et INT demandent une fermeture via
+// taskkill, KILL force la terminaison (/F)
+var signalNames = map[string]syscall.Signal{
+	"INT":  syscall.SIGINT,
+	"KILL": syscall.SIGKILL,
+	"TERM": syscall.SIGTERM,
+}
+
+func sendSignal(pid int, sig syscall.Signal) error {
+	args := []string{"/PID", strconv.Itoa(pid), "/T"}
+	if sig == syscall.SIGKILL {
+		args = append(args, "/F")
+	}
+	return exec.Command("taskkill", args...).Run()
+}
+
+func processAlive(pid int) bool {
+	out, err := exec.Command("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(out), "\""+strconv.Itoa(pid)+"\"")
+}
@@ -0,0 +1,172 @@
+package procops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Snapshot est un relevé des processus à un instant donné
+type Snapshot struct {
+	Hostname  string        `json:"hostname"`
+	TakenAt   time.Time     `json:"taken_at"`
+	Backend   string        `json:"backend"`
+	Processes []ProcessInfo `json:"processes"`
+}
+
+// ProcessDelta compare un même processus entre deux relevés
+type ProcessDelta struct {
+	Process    ProcessInfo   `json:"process"` // état dans le second relevé
+	RSSBefore  uint64        `json:"rss_before_bytes"`
+	RSSDelta   int64         `json:"rss_delta_bytes"`
+	CPUDelta   time.Duration `json:"cpu_delta_ns"`
+	CPUPercent float64       `json:"cpu_percent"` // %CPU moyen entre les deux relevés
+}
+
+// SnapshotDiff résume ce qui a changé entre deux relevés
+type SnapshotDiff struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Started   []ProcessInfo  `json:"started"`
+	Exited    []ProcessInfo  `json:"exited"`
+	MemGrowth []ProcessDelta `json:"mem_growth"` // plus fortes hausses de RSS
+	CPUGrowth []ProcessDelta `json:"cpu_growth"` // plus gros consommateurs de CPU sur la période
+}
+
+// Format des noms de fichiers de relevé, à la milliseconde : l'ordre
+// alphabétique des noms est l'ordre chronologique
+const snapshotLayout = "20060102-150405.000"
+
+// TakeSnapshot relève tous les processus
+func TakeSnapshot() (Snapshot, error) {
+	procs, err := backend.Processes()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	host, _ := os.Hostname()
+	SortProcesses(procs, SortPID, "")
+	return Snapshot{Hostname: host, TakenAt: time.Now(), Backend: backend.Name(), Processes: procs}, nil
+}
+
+// SaveSnapshot écrit le relevé dans dir sous snapshot_<date>.json sans jamais
+// écraser un relevé existant : si le nom est pris, la date du nom est avancée
+// d'une milliseconde jusqu'à trouver un nom libre
+func SaveSnapshot(dir string, s Snapshot) (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	for at := s.TakenAt; ; at = at.Add(time.Millisecond) {
+		path := filepath.Join(dir, "snapshot_"+at.Format(snapshotLayout)+".json")
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path)
+			return "", err
+		}
+		return path, nil
+	}
+}
+
+// LoadSnapshot relit un relevé enregistré
+func LoadSnapshot(path string) (Snapshot, error) {
+	var s Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("relevé invalide %s : %w", path, err)
+	}
+	return s, nil
+}
+
+// ListSnapshots retourne les relevés de dir, du plus ancien au plus récent
+func ListSnapshots(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "snapshot_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// DiffSnapshots compare deux relevés et garde les topN plus fortes
+// progressions de mémoire et de CPU (0 = toutes)
+func DiffSnapshots(before, after Snapshot, topN int) SnapshotDiff {
+	diff := SnapshotDiff{From: before.TakenAt, To: after.TakenAt,
+		Started: []ProcessInfo{}, Exited: []ProcessInfo{}, MemGrowth: []ProcessDelta{}, CPUGrowth: []ProcessDelta{}}
+
+	old := make(map[int]ProcessInfo, len(before.Processes))
+	for _, p := range before.Processes {
+		old[p.PID] = p
+	}
+	elapsed := after.TakenAt.Sub(before.TakenAt)
+
+	seen := map[int]bool{}
+	var deltas []ProcessDelta
+	for _, p := range after.Processes {
+		prev, ok := old[p.PID]
+		if !ok || !sameProcess(prev, p) {
+			diff.Started = append(diff.Started, p)
+			continue
+		}
+		seen[p.PID] = true
+		d := ProcessDelta{
+			Process:   p,
+			RSSBefore: prev.RSS,
+			RSSDelta:  int64(p.RSS) - int64(prev.RSS),
+			CPUDelta:  p.CPUTime - prev.CPUTime,
+		}
+		d.CPUPercent = percent(d.CPUDelta, elapsed)
+		deltas = append(deltas, d)
+	}
+	for _, p := range before.Processes {
+		if !seen[p.PID] {
+			diff.Exited = append(diff.Exited, p)
+		}
+	}
+
+	diff.MemGrowth = topDeltas(deltas, topN, func(d ProcessDelta) bool { return d.RSSDelta > 0 },
+		func(a, b ProcessDelta) bool { return a.RSSDelta > b.RSSDelta })
+	diff.CPUGrowth = topDeltas(deltas, topN, func(d ProcessDelta) bool { return d.CPUDelta > 0 },
+		func(a, b ProcessDelta) bool { return a.CPUDelta > b.CPUDelta })
+	return diff
+}
+
+// sameProcess vérifie qu'un PID n'a pas été réattribué entre deux relevés.
+// Sans heure de démarrage (tasklist), on se rabat sur le nom.
+func sameProcess(a, b ProcessInfo) bool {
+	if a.StartTime.IsZero() || b.StartTime.IsZero() {
+		return a.Name == b.Name
+	}
+	return sameStart(a.StartTime, b.StartTime)
+}
+
+// topDeltas filtre, trie et tronque une copie des écarts
+func topDeltas(deltas []ProcessDelta, topN int, keep func(ProcessDelta) bool, less func(a, b ProcessDelta) bool) []ProcessDelta {
+	out := []ProcessDelta{}
+	for _, d := range deltas {
+		if keep(d) {
+			out = append(out, d)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+	if topN > 0 && len(out) > topN {
+		out = out[:topN]
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package procops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	boot := t0.Add(-24 * time.Hour)
+	before := Snapshot{TakenAt: t0, Processes: []ProcessInfo{
+		{PID: 1, Name: "init", StartTime: boot, RSS: 10 * mb, CPUTime: time.Second},
+		{PID: 2, Name: "api", StartTime: boot, RSS: 100 * mb, CPUTime: 10 * time.Second},
+		{PID: 3, Name: "cache", StartTime: boot, RSS: 500 * mb, CPUTime: time.Minute},
+		{PID: 4, Name: "cron", StartTime: boot, RSS: 5 * mb},
+		{PID: 5, Name: "worker", StartTime: boot, RSS: 50 * mb},
+		{PID: 6, Name: "tasklist.exe", RSS: 8 * mb}, // sans heure de démarrage
+	}}
+	after := Snapshot{TakenAt: t0.Add(10 * time.Second), Processes: []ProcessInfo{
+		{PID: 1, Name: "init", StartTime: boot, RSS: 10 * mb, CPUTime: time.Second},
+		{PID: 2, Name: "api", StartTime: boot.Add(time.Second), RSS: 300 * mb, CPUTime: 15 * time.Second}, // gigue ps
+		{PID: 3, Name: "cache", StartTime: boot, RSS: 400 * mb, CPUTime: time.Minute + 10*time.Second},
+		{PID: 5, Name: "worker", StartTime: t0.Add(5 * time.Second), RSS: 60 * mb}, // PID réattribué
+		{PID: 6, Name: "tasklist.exe", RSS: 9 * mb},
+		{PID: 7, Name: "deploy", StartTime: t0.Add(2 * time.Second)},
+	}}
+
+	diff := DiffSnapshots(before, after, 0)
+	if !diff.From.Equal(before.TakenAt) || !diff.To.Equal(after.TakenAt) {
+		t.Errorf("période = %v -> %v", diff.From, diff.To)
+	}
+	if got, want := pids(diff.Started), []int{5, 7}; !equalInts(got, want) {
+		t.Errorf("démarrés = %v, attendu %v", got, want)
+	}
+	if got, want := pids(diff.Exited), []int{4, 5}; !equalInts(got, want) {
+		t.Errorf("terminés = %v, attendu %v", got, want)
+	}
+
+	// Hausses de mémoire seulement, de la plus forte à la plus faible
+	if len(diff.MemGrowth) != 2 {
+		t.Fatalf("hausses mémoire = %+v", diff.MemGrowth)
+	}
+	api, tl := diff.MemGrowth[0], diff.MemGrowth[1]
+	if api.Process.PID != 2 || api.RSSBefore != 100*mb || api.RSSDelta != 200*mb {
+		t.Errorf("api = %+v", api)
+	}
+	if tl.Process.PID != 6 || tl.RSSDelta != 1*mb {
+		t.Errorf("tasklist = %+v", tl)
+	}
+
+	// CPU : cache a consommé 10 s sur 10 s, api 5 s
+	if len(diff.CPUGrowth) != 2 {
+		t.Fatalf("CPU = %+v", diff.CPUGrowth)
+	}
+	cache := diff.CPUGrowth[0]
+	if cache.Process.PID != 3 || cache.CPUDelta != 10*time.Second || cache.CPUPercent != 100 || cache.RSSDelta != -100*mb {
+		t.Errorf("cache = %+v", cache)
+	}
+	if d := diff.CPUGrowth[1]; d.Process.PID != 2 || d.CPUPercent != 50 {
+		t.Errorf("api = %+v", d)
+	}
+
+	if top := DiffSnapshots(before, after, 1); len(top.MemGrowth) != 1 || len(top.CPUGrowth) != 1 || len(top.Started) != 2 {
+		t.Errorf("topN=1 : %+v", top)
+	}
+}
+
+func TestDiffSnapshotsEmpty(t *testing.T) {
+	diff := DiffSnapshots(Snapshot{}, Snapshot{}, 5)
+	// Listes vides plutôt que null dans le JSON
+	if diff.Started == nil || diff.Exited == nil || diff.MemGrowth == nil || diff.CPUGrowth == nil {
+		t.Errorf("listes nil : %+v", diff)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	first := Snapshot{Hostname: "h", TakenAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), Backend: "fake",
+		Processes: []ProcessInfo{{PID: 42, Name: "api", RSS: mb}}}
+	second := first
+	second.TakenAt = first.TakenAt.Add(time.Hour)
+
+	var saved []string
+	for _, s := range []Snapshot{second, first} {
+		path, err := SaveSnapshot(dir, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved = append(saved, path)
+	}
+	if filepath.Base(saved[1]) != "snapshot_20240501-100000.000.json" {
+		t.Errorf("nom = %s", saved[1])
+	}
+
+	list, err := ListSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0] != saved[1] || list[1] != saved[0] {
+		t.Errorf("relevés = %v, attendu du plus ancien au plus récent", list)
+	}
+
+	loaded, err := LoadSnapshot(list[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.TakenAt.Equal(first.TakenAt) || len(loaded.Processes) != 1 || loaded.Processes[0].Name != "api" {
+		t.Errorf("relevé relu = %+v", loaded)
+	}
+
+	// Même instant : un second fichier, sans écraser le premier
+	again, err := SaveSnapshot(dir, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(again) != "snapshot_20240501-100000.001.json" {
+		t.Errorf("nom après collision = %s", again)
+	}
+	if list, _ := ListSnapshots(dir); len(list) != 3 || list[0] != saved[1] || list[1] != again {
+		t.Errorf("relevés = %v", list)
+	}
+	if s, err := LoadSnapshot(saved[1]); err != nil || !s.TakenAt.Equal(first.TakenAt) {
+		t.Errorf("premier relevé altéré : %+v, %v", s, err)
+	}
+
+	bad := filepath.Join(dir, "snapshot_invalide.json")
+	if err := os.WriteFile(bad, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(bad); err == nil {
+		t.Error("relevé invalide accepté")
+	}
+}
@@ -0,0 +1,104 @@
+package procops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKey est le critère de tri des processus
+type SortKey string
+
+const (
+	SortNone  SortKey = ""      // ordre du backend (PID croissant pour /proc)
+	SortCPU   SortKey = "cpu"   // %CPU (Sampler), puis temps CPU cumulé
+	SortMem   SortKey = "mem"   // mémoire virtuelle
+	SortRSS   SortKey = "rss"   // mémoire résidente
+	SortStart SortKey = "start" // heure de démarrage
+	SortPID   SortKey = "pid"
+	SortName  SortKey = "name"
+)
+
+// Ordre de tri
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// ListOptions paramètre ListProcesses
+type ListOptions struct {
+	TopN   int     // 0 = tous
+	SortBy SortKey // SortNone = pas de tri
+	Order  string  // OrderAsc, OrderDesc ou vide (ordre naturel du critère)
+}
+
+// ParseSortKey valide un critère de tri saisi par l'utilisateur
+func ParseSortKey(s string) (SortKey, error) {
+	key := SortKey(strings.ToLower(strings.TrimSpace(s)))
+	switch key {
+	case SortNone, SortCPU, SortMem, SortRSS, SortStart, SortPID, SortName:
+		return key, nil
+	}
+	return SortNone, fmt.Errorf("critère de tri inconnu : %s (cpu, mem, rss, start, pid, name)", s)
+}
+
+// ParseOrder valide un ordre de tri ("asc", "desc" ou vide)
+func ParseOrder(s string) (string, error) {
+	order := strings.ToLower(strings.TrimSpace(s))
+	switch order {
+	case "", OrderAsc, OrderDesc:
+		return order, nil
+	}
+	return "", fmt.Errorf("ordre de tri inconnu : %s (asc, desc)", s)
+}
+
+// defaultDescending indique l'ordre naturel d'un critère : les plus gros
+// consommateurs et les plus récents d'abord, PID et nom en ordre croissant
+func defaultDescending(key SortKey) bool {
+	switch key {
+	case SortCPU, SortMem, SortRSS, SortStart:
+		return true
+	}
+	return false
+}
+
+// SortProcesses trie la liste sur place. Le PID départage les égalités.
+func SortProcesses(procs []ProcessInfo, key SortKey, order string) {
+	if key == SortNone {
+		return
+	}
+	desc := defaultDescending(key)
+	if order != "" {
+		desc = order == OrderDesc
+	}
+
+	less := func(a, b ProcessInfo) bool {
+		switch key {
+		case SortCPU:
+			if a.CPUPercent != b.CPUPercent {
+				return a.CPUPercent < b.CPUPercent
+			}
+			return a.CPUTime < b.CPUTime
+		case SortMem:
+			return a.VSize < b.VSize
+		case SortRSS:
+			return a.RSS < b.RSS
+		case SortStart:
+			return a.StartTime.Before(b.StartTime)
+		case SortName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return a.PID < b.PID
+	}
+
+	sort.SliceStable(procs, func(i, j int) bool {
+		a, b := procs[i], procs[j]
+		if less(a, b) {
+			return !desc
+		}
+		if less(b, a) {
+			return desc
+		}
+		return a.PID < b.PID
+	})
+}
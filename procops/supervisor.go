@@ -0,0 +1,373 @@
+package procops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Politiques de redémarrage des services
+const (
+	RestartNever     = "never"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// États d'un service supervisé
+const (
+	StateStopped = "stopped" // jamais démarré ou arrêté par l'utilisateur
+	StateRunning = "running"
+	StateBackoff = "backoff" // en attente avant redémarrage
+	StateExited  = "exited"  // terminé sans redémarrage prévu
+	StateFailed  = "failed"  // échec sans redémarrage prévu
+)
+
+// ServiceSpec décrit une commande gérée, telle que définie dans la config
+type ServiceSpec struct {
+	Name          string   `json:"name"`
+	Command       string   `json:"command"`
+	Args          []string `json:"args"`
+	Dir           string   `json:"dir"`
+	Env           []string `json:"env"`             // KEY=VALUE, ajoutés à l'environnement courant
+	Restart       string   `json:"restart"`         // never (défaut), on-failure, always
+	BackoffSec    int      `json:"backoff_sec"`     // premier délai avant redémarrage (défaut 1)
+	MaxBackoffSec int      `json:"max_backoff_sec"` // plafond du délai, doublé à chaque échec (défaut 60)
+	MaxRestarts   int      `json:"max_restarts"`    // 0 = illimité
+}
+
+// ServiceStatus est l'état courant d'un service
+type ServiceStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	PID       int       `json:"pid,omitempty"`
+	Restarts  int       `json:"restarts"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	ExitCode  int       `json:"exit_code"` // dernier code de sortie (-1 si tué par un signal)
+	LastError string    `json:"last_error,omitempty"`
+	StdoutLog string    `json:"stdout_log"`
+	StderrLog string    `json:"stderr_log"`
+}
+
+// service est l'état interne d'un service supervisé
+type service struct {
+	spec   ServiceSpec
+	status ServiceStatus
+	stop   chan struct{} // fermé pour demander l'arrêt
+	done   chan struct{} // fermé quand la boucle de supervision se termine
+}
+
+// Supervisor lance les services, capture leurs sorties et les redémarre
+// selon leur politique
+type Supervisor struct {
+	mu       sync.Mutex
+	logDir   string
+	grace    time.Duration
+	services map[string]*service
+
+	// OnEvent, s'il est défini, est appelé à chaque démarrage, arrêt ou redémarrage
+	OnEvent func(name, message string)
+}
+
+// Nom de service admis : il sert de nom de fichier pour les journaux et ne
+// doit pas permettre d'écrire hors de leur répertoire
+var serviceNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ValidateServices vérifie les services de la config : nom sûr et unique,
+// commande présente, politique de redémarrage connue
+func ValidateServices(specs []ServiceSpec) error {
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		if spec.Name == "" || spec.Command == "" {
+			return fmt.Errorf("service invalide : nom et commande obligatoires")
+		}
+		if !serviceNameRe.MatchString(spec.Name) || spec.Name == "." || spec.Name == ".." {
+			return fmt.Errorf("service %q : nom invalide (lettres, chiffres, '.', '_' et '-' uniquement)", spec.Name)
+		}
+		if seen[spec.Name] {
+			return fmt.Errorf("service %s défini deux fois", spec.Name)
+		}
+		seen[spec.Name] = true
+		switch spec.Restart {
+		case "", RestartNever, RestartOnFailure, RestartAlways:
+		default:
+			return fmt.Errorf("service %s : politique de redémarrage inconnue %s (never, on-failure, always)", spec.Name, spec.Restart)
+		}
+	}
+	return nil
+}
+
+// NewSupervisor valide les services et prépare le répertoire des journaux.
+// grace est le délai laissé après TERM avant KILL lors d'un arrêt.
+func NewSupervisor(specs []ServiceSpec, logDir string, grace time.Duration) (*Supervisor, error) {
+	if err := ValidateServices(specs); err != nil {
+		return nil, err
+	}
+	s := &Supervisor{logDir: logDir, grace: grace, services: map[string]*service{}}
+	for _, spec := range specs {
+		if spec.Restart == "" {
+			spec.Restart = RestartNever
+		}
+		s.services[spec.Name] = &service{
+			spec: spec,
+			status: ServiceStatus{
+				Name:      spec.Name,
+				State:     StateStopped,
+				StdoutLog: filepath.Join(logDir, spec.Name+".out.log"),
+				StderrLog: filepath.Join(logDir, spec.Name+".err.log"),
+			},
+		}
+	}
+	if len(specs) > 0 {
+		if err := os.MkdirAll(logDir, os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Names retourne les noms des services triés
+func (s *Supervisor) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.services))
+	for name := range s.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Status retourne l'état de tous les services, triés par nom
+func (s *Supervisor) Status() []ServiceStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]ServiceStatus, 0, len(s.services))
+	for _, svc := range s.services {
+		statuses = append(statuses, svc.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Start démarre un service et sa boucle de supervision
+func (s *Supervisor) Start(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	svc, ok := s.services[name]
+	if !ok {
+		return fmt.Errorf("service inconnu : %s", name)
+	}
+	if svc.done != nil {
+		select {
+		case <-svc.done:
+		default:
+			return fmt.Errorf("le service %s est déjà démarré", name)
+		}
+	}
+	svc.stop = make(chan struct{})
+	svc.done = make(chan struct{})
+	svc.status.Restarts = 0
+	svc.status.LastError = ""
+	go s.supervise(svc)
+	return nil
+}
+
+// Stop arrête un service (TERM, puis KILL après le délai de grâce)
+func (s *Supervisor) Stop(name string) error {
+	s.mu.Lock()
+	svc, ok := s.services[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("service inconnu : %s", name)
+	}
+	done := svc.done
+	if done == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("le service %s n'est pas démarré", name)
+	}
+	select {
+	case <-done:
+		s.mu.Unlock()
+		return fmt.Errorf("le service %s n'est pas démarré", name)
+	default:
+	}
+	close(svc.stop)
+	s.mu.Unlock()
+
+	<-done
+	return nil
+}
+
+// StopAll arrête tous les services démarrés
+func (s *Supervisor) StopAll() {
+	for _, name := range s.Names() {
+		s.Stop(name)
+	}
+}
+
+// supervise exécute le service et le redémarre selon sa politique
+func (s *Supervisor) supervise(svc *service) {
+	defer close(svc.done)
+
+	backoff := newBackoff(svc.spec)
+	for {
+		started := time.Now()
+		exitCode, stopped, err := s.runOnce(svc)
+		if stopped {
+			s.setState(svc, StateStopped, 0)
+			s.event(svc.spec.Name, "arrêté")
+			return
+		}
+
+		failed := err != nil || exitCode != 0
+		s.mu.Lock()
+		svc.status.ExitCode = exitCode
+		if err != nil {
+			svc.status.LastError = err.Error()
+		}
+		restarts := svc.status.Restarts
+		s.mu.Unlock()
+
+		if !shouldRestart(svc.spec, failed, restarts) {
+			state := StateExited
+			if failed {
+				state = StateFailed
+			}
+			s.setState(svc, state, 0)
+			s.event(svc.spec.Name, fmt.Sprintf("terminé (code %d), pas de redémarrage", exitCode))
+			return
+		}
+
+		delay := backoff.next(time.Since(started))
+		s.setState(svc, StateBackoff, 0)
+		s.event(svc.spec.Name, fmt.Sprintf("terminé (code %d), redémarrage dans %s", exitCode, delay))
+
+		select {
+		case <-svc.stop:
+			s.setState(svc, StateStopped, 0)
+			s.event(svc.spec.Name, "arrêté")
+			return
+		case <-time.After(delay):
+		}
+
+		s.mu.Lock()
+		svc.status.Restarts++
+		s.mu.Unlock()
+	}
+}
+
+// shouldRestart applique la politique du service après une fin d'exécution,
+// restarts étant le nombre de redémarrages déjà effectués
+func shouldRestart(spec ServiceSpec, failed bool, restarts int) bool {
+	if spec.MaxRestarts > 0 && restarts >= spec.MaxRestarts {
+		return false
+	}
+	return spec.Restart == RestartAlways || (spec.Restart == RestartOnFailure && failed)
+}
+
+// backoff calcule les délais entre redémarrages : doublés à chaque fin,
+// plafonnés, et remis au délai initial après une exécution plus longue que le plafond
+type backoff struct {
+	initial, max, delay time.Duration
+}
+
+func newBackoff(spec ServiceSpec) *backoff {
+	initial := durationOr(spec.BackoffSec, time.Second)
+	return &backoff{initial: initial, max: durationOr(spec.MaxBackoffSec, time.Minute), delay: initial}
+}
+
+// next retourne le délai avant le prochain redémarrage d'un service qui a tourné ran
+func (b *backoff) next(ran time.Duration) time.Duration {
+	if ran >= b.max {
+		b.delay = b.initial
+	}
+	delay := b.delay
+	b.delay = min(b.delay*2, b.max)
+	return delay
+}
+
+// runOnce lance la commande une fois et attend sa fin ou une demande d'arrêt
+func (s *Supervisor) runOnce(svc *service) (exitCode int, stopped bool, err error) {
+	stdout, err := os.OpenFile(svc.status.StdoutLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return -1, false, err
+	}
+	defer stdout.Close()
+	stderr, err := os.OpenFile(svc.status.StderrLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return -1, false, err
+	}
+	defer stderr.Close()
+
+	cmd := exec.Command(svc.spec.Command, svc.spec.Args...)
+	cmd.Dir = svc.spec.Dir
+	cmd.Env = append(os.Environ(), svc.spec.Env...)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Start(); err != nil {
+		return -1, false, err
+	}
+
+	s.mu.Lock()
+	svc.status.State = StateRunning
+	svc.status.PID = cmd.Process.Pid
+	svc.status.StartedAt = time.Now()
+	s.mu.Unlock()
+	s.event(svc.spec.Name, fmt.Sprintf("démarré (PID %d)", cmd.Process.Pid))
+
+	waitCh := make(chan error, 1)
+	go func() { waitCh <- cmd.Wait() }()
+
+	select {
+	case err := <-waitCh:
+		return exitCodeOf(cmd, err)
+	case <-svc.stop:
+		// Windows ne supporte pas SIGTERM : on tue directement
+		if cmd.Process.Signal(syscall.SIGTERM) != nil {
+			cmd.Process.Kill()
+		}
+		select {
+		case <-waitCh:
+		case <-time.After(s.grace):
+			cmd.Process.Kill()
+			<-waitCh
+		}
+		return 0, true, nil
+	}
+}
+
+// exitCodeOf distingue une sortie normale (code) d'une erreur d'exécution
+func exitCodeOf(cmd *exec.Cmd, err error) (int, bool, error) {
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return -1, false, err
+	}
+	return cmd.ProcessState.ExitCode(), false, nil
+}
+
+func (s *Supervisor) setState(svc *service, state string, pid int) {
+	s.mu.Lock()
+	svc.status.State = state
+	svc.status.PID = pid
+	s.mu.Unlock()
+}
+
+func (s *Supervisor) event(name, message string) {
+	if s.OnEvent != nil {
+		s.OnEvent(name, message)
+	}
+}
+
+// durationOr convertit des secondes de config, avec une valeur par défaut
+func durationOr(sec int, def time.Duration) time.Duration {
+	if sec <= 0 {
+		return def
+	}
+	return time.Duration(sec) * time.Second
+}
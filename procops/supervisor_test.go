@@ -0,0 +1,191 @@
+package procops
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestShouldRestart(t *testing.T) {
+	tests := []struct {
+		restart  string
+		failed   bool
+		restarts int
+		max      int
+		want     bool
+	}{
+		{RestartNever, true, 0, 0, false},
+		{RestartOnFailure, true, 0, 0, true},
+		{RestartOnFailure, false, 0, 0, false},
+		{RestartAlways, false, 0, 0, true},
+		{RestartAlways, true, 41, 0, true}, // 0 = illimité
+		{RestartAlways, true, 2, 3, true},
+		{RestartAlways, true, 3, 3, false},
+		{RestartOnFailure, true, 5, 3, false},
+	}
+	for _, tt := range tests {
+		spec := ServiceSpec{Restart: tt.restart, MaxRestarts: tt.max}
+		if got := shouldRestart(spec, tt.failed, tt.restarts); got != tt.want {
+			t.Errorf("%s, échec=%v, %d/%d redémarrages : %v, attendu %v", tt.restart, tt.failed, tt.restarts, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(ServiceSpec{BackoffSec: 1, MaxBackoffSec: 5})
+	var delays []time.Duration
+	for i := 0; i < 5; i++ {
+		delays = append(delays, b.next(0))
+	}
+	want := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("délais = %v, attendu %v", delays, want)
+		}
+	}
+
+	// Une exécution plus longue que le plafond repart du délai initial
+	if d := b.next(5 * time.Second); d != time.Second {
+		t.Errorf("après une longue exécution : %s, attendu 1s", d)
+	}
+	if d := b.next(time.Second); d != 2*time.Second {
+		t.Errorf("ensuite : %s, attendu 2s", d)
+	}
+
+	// Valeurs par défaut : 1 s, plafond 1 min
+	def := newBackoff(ServiceSpec{})
+	if def.initial != time.Second || def.max != time.Minute {
+		t.Errorf("défauts = %s / %s", def.initial, def.max)
+	}
+}
+
+func TestNewSupervisorValidation(t *testing.T) {
+	dir := t.TempDir()
+	for _, specs := range [][]ServiceSpec{
+		{{Name: "", Command: "true"}},
+		{{Name: "a", Command: ""}},
+		{{Name: "a", Command: "true"}, {Name: "a", Command: "true"}},
+		{{Name: "a", Command: "true", Restart: "parfois"}},
+		{{Name: "../../etc/cron.d/x", Command: "true"}},
+		{{Name: "a/b", Command: "true"}},
+		{{Name: "..", Command: "true"}},
+		{{Name: "nom avec espace", Command: "true"}},
+	} {
+		if _, err := NewSupervisor(specs, dir, time.Second); err == nil {
+			t.Errorf("%+v : erreur attendue", specs)
+		}
+	}
+	if err := ValidateServices([]ServiceSpec{{Name: "api-v2.worker_1", Command: "true"}}); err != nil {
+		t.Errorf("nom valide refusé : %v", err)
+	}
+	s, err := NewSupervisor([]ServiceSpec{{Name: "a", Command: "true"}}, dir, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := s.Status()[0]; st.State != StateStopped || s.services["a"].spec.Restart != RestartNever {
+		t.Errorf("état initial = %+v, politique %s", st, s.services["a"].spec.Restart)
+	}
+}
+
+// waitService attend la fin de la boucle de supervision d'un service
+func waitService(t *testing.T, s *Supervisor, name string) ServiceStatus {
+	t.Helper()
+	s.mu.Lock()
+	done := s.services[name].done
+	s.mu.Unlock()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		s.StopAll()
+		t.Fatalf("%s toujours supervisé", name)
+	}
+	for _, st := range s.Status() {
+		if st.Name == name {
+			return st
+		}
+	}
+	t.Fatalf("service %s absent", name)
+	return ServiceStatus{}
+}
+
+func TestSupervisorRestartPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh requis")
+	}
+	specs := []ServiceSpec{
+		{Name: "echec", Command: "sh", Args: []string{"-c", "exit 3"}, Restart: RestartOnFailure, BackoffSec: 1, MaxRestarts: 1},
+		{Name: "succes", Command: "sh", Args: []string{"-c", "exit 0"}, Restart: RestartOnFailure},
+		{Name: "unique", Command: "sh", Args: []string{"-c", "exit 4"}},
+	}
+	s, err := NewSupervisor(specs, t.TempDir(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	eventCh := make(chan string, 16)
+	s.OnEvent = func(name, message string) { eventCh <- name + " : " + message }
+	for _, spec := range specs {
+		if err := s.Start(spec.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// on-failure : un redémarrage après 1 s, puis abandon (max_restarts)
+	if st := waitService(t, s, "echec"); st.State != StateFailed || st.Restarts != 1 || st.ExitCode != 3 || st.PID != 0 {
+		t.Errorf("echec = %+v", st)
+	}
+	// on-failure sur une sortie normale : pas de redémarrage
+	if st := waitService(t, s, "succes"); st.State != StateExited || st.Restarts != 0 {
+		t.Errorf("succes = %+v", st)
+	}
+	// never : l'échec est constaté sans redémarrage
+	if st := waitService(t, s, "unique"); st.State != StateFailed || st.Restarts != 0 || st.ExitCode != 4 {
+		t.Errorf("unique = %+v", st)
+	}
+
+	close(eventCh)
+	for e := range eventCh {
+		events = append(events, e)
+	}
+	var backoffs int
+	for _, e := range events {
+		if e == "echec : terminé (code 3), redémarrage dans 1s" {
+			backoffs++
+		}
+	}
+	if backoffs != 1 {
+		t.Errorf("événements = %q", events)
+	}
+}
+
+func TestSupervisorStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep requis")
+	}
+	s, err := NewSupervisor([]ServiceSpec{{Name: "dormeur", Command: "sleep", Args: []string{"30"}, Restart: RestartAlways}}, t.TempDir(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start("dormeur"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start("dormeur"); err == nil {
+		t.Error("double démarrage accepté")
+	}
+	for deadline := time.Now().Add(5 * time.Second); s.Status()[0].PID == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("service non démarré")
+		}
+	}
+
+	// Un arrêt demandé ne déclenche pas la politique always
+	if err := s.Stop("dormeur"); err != nil {
+		t.Fatal(err)
+	}
+	if st := s.Status()[0]; st.State != StateStopped || st.Restarts != 0 || st.PID != 0 {
+		t.Errorf("après arrêt : %+v", st)
+	}
+	if err := s.Stop("dormeur"); err == nil {
+		t.Error("arrêt d'un service arrêté accepté")
+	}
+}
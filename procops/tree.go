@@ -0,0 +1,85 @@
+package procops
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ProcessNode est un nœud de l'arbre des processus
+type ProcessNode struct {
+	Process  ProcessInfo    `json:"process"`
+	Children []*ProcessNode `json:"children,omitempty"`
+}
+
+// BuildTree relie chaque processus à son parent (PPID). Les processus dont le
+// parent est absent de la liste (PID 1, threads noyau, parent terminé) sont des racines.
+func BuildTree(procs []ProcessInfo) []*ProcessNode {
+	nodes := make(map[int]*ProcessNode, len(procs))
+	for _, p := range procs {
+		nodes[p.PID] = &ProcessNode{Process: p}
+	}
+
+	var roots []*ProcessNode
+	for _, p := range procs {
+		node := nodes[p.PID]
+		parent, ok := nodes[p.PPID]
+		if !ok || p.PPID == p.PID {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	for _, node := range nodes {
+		sortNodes(node.Children)
+	}
+	sortNodes(roots)
+	return roots
+}
+
+// Subtree retourne le sous-arbre enraciné au PID demandé
+func Subtree(procs []ProcessInfo, pid int) (*ProcessNode, error) {
+	var find func(nodes []*ProcessNode) *ProcessNode
+	find = func(nodes []*ProcessNode) *ProcessNode {
+		for _, n := range nodes {
+			if n.Process.PID == pid {
+				return n
+			}
+			if found := find(n.Children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if node := find(BuildTree(procs)); node != nil {
+		return node, nil
+	}
+	return nil, fmt.Errorf("processus %d introuvable", pid)
+}
+
+// RenderTree écrit l'arbre indenté à la manière de pstree -A
+func RenderTree(w io.Writer, roots []*ProcessNode) {
+	for _, root := range roots {
+		fmt.Fprintf(w, "%s(%d)\n", root.Process.Name, root.Process.PID)
+		renderChildren(w, root.Children, "")
+	}
+}
+
+func renderChildren(w io.Writer, children []*ProcessNode, prefix string) {
+	for i, child := range children {
+		branch, indent := " |-", " |  "
+		if i == len(children)-1 {
+			branch, indent = " `-", "    "
+		}
+		fmt.Fprintf(w, "%s%s%s(%d)\n", prefix, branch, child.Process.Name, child.Process.PID)
+		renderChildren(w, child.Children, prefix+indent)
+	}
+}
+
+// sortNodes ordonne des nœuds frères par PID
+func sortNodes(nodes []*ProcessNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Process.PID < nodes[j].Process.PID
+	})
+}
@@ -0,0 +1,141 @@
+package procops
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Subreapers liste les noms exacts des processus connus pour adopter les
+// orphelins de leurs descendants (PR_SET_CHILD_SUBREAPER), en plus de PID 1.
+// systemd couvre aussi systemd --user ; /proc tronque les noms à 15
+// caractères, d'où containerd-shim pour containerd-shim-runc-v2.
+var Subreapers = []string{"systemd", "tini", "dumb-init", "containerd-shim", "containerd-shim-runc-v2",
+	"s6-svscan", "runsvdir", "catatonit"}
+
+// ZombieGroup rassemble les zombies d'un même parent
+type ZombieGroup struct {
+	Parent      ProcessInfo   `json:"parent"`
+	Zombies     []ProcessInfo `json:"zombies"`
+	Signal      string        `json:"signal"` // signal suggéré au parent
+	Remediation string        `json:"remediation"`
+}
+
+// Orphan est un processus adopté par PID 1 ou un subreaper alors que sa
+// session d'origine a disparu
+type Orphan struct {
+	Process     ProcessInfo   `json:"process"`
+	Reaper      ProcessInfo   `json:"reaper"`
+	Age         time.Duration `json:"age_ns"`
+	Remediation string        `json:"remediation"`
+}
+
+// ZombieReport est le diagnostic des zombies et des orphelins
+type ZombieReport struct {
+	Zombies int           `json:"zombies"`
+	Groups  []ZombieGroup `json:"groups"`
+	Orphans []Orphan      `json:"orphans"`
+	// OrphansChecked est faux si le backend ne fournit pas les sessions (ps, tasklist)
+	OrphansChecked bool `json:"orphans_checked"`
+}
+
+// DiagnoseZombies liste les zombies (état Z) groupés par parent et les
+// orphelins vivants depuis au moins minAge
+func DiagnoseZombies(minAge time.Duration) (ZombieReport, error) {
+	procs, err := backend.Processes()
+	if err != nil {
+		return ZombieReport{}, err
+	}
+	return diagnose(procs, minAge, time.Now()), nil
+}
+
+// diagnose construit le rapport à partir d'un relevé des processus
+func diagnose(procs []ProcessInfo, minAge time.Duration, now time.Time) ZombieReport {
+	byPID := make(map[int]ProcessInfo, len(procs))
+	for _, p := range procs {
+		byPID[p.PID] = p
+	}
+	report := ZombieReport{Groups: []ZombieGroup{}, Orphans: []Orphan{}}
+
+	groups := map[int]*ZombieGroup{}
+	for _, p := range procs {
+		if p.State != "Z" {
+			continue
+		}
+		report.Zombies++
+		g, ok := groups[p.PPID]
+		if !ok {
+			parent, found := byPID[p.PPID]
+			if !found {
+				parent = ProcessInfo{PID: p.PPID, UID: -1, Name: "?"}
+			}
+			g = &ZombieGroup{Parent: parent, Signal: "CHLD"}
+			g.Remediation = zombieRemediation(parent)
+			groups[p.PPID] = g
+		}
+		g.Zombies = append(g.Zombies, p)
+	}
+	for _, g := range groups {
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if len(report.Groups[i].Zombies) != len(report.Groups[j].Zombies) {
+			return len(report.Groups[i].Zombies) > len(report.Groups[j].Zombies)
+		}
+		return report.Groups[i].Parent.PID < report.Groups[j].Parent.PID
+	})
+
+	for _, p := range procs {
+		if p.SID != 0 {
+			report.OrphansChecked = true
+		}
+		reaper, ok := byPID[p.PPID]
+		if !ok || !isReaper(reaper) || p.State == "Z" || p.StartTime.IsZero() {
+			continue
+		}
+		// Un démon est chef de sa session (setsid) ; un orphelin garde la
+		// session du shell ou du programme qui l'a lancé, disparu depuis
+		if p.SID == 0 || p.SID == p.PID {
+			continue
+		}
+		if _, alive := byPID[p.SID]; alive {
+			continue
+		}
+		age := now.Sub(p.StartTime)
+		if age < minAge {
+			continue
+		}
+		report.Orphans = append(report.Orphans, Orphan{
+			Process: p,
+			Reaper:  reaper,
+			Age:     age,
+			Remediation: fmt.Sprintf("vérifier si '%s' est encore utile, sinon kill -TERM %d (session %d disparue)",
+				p.Name, p.PID, p.SID),
+		})
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Age > report.Orphans[j].Age })
+	return report
+}
+
+// isReaper indique si le processus adopte les orphelins
+func isReaper(p ProcessInfo) bool {
+	if p.PID == 1 {
+		return true
+	}
+	// Correspondance exacte : systemd-journald ou systemd-udevd n'adoptent rien
+	for _, name := range Subreapers {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// zombieRemediation suggère comment faire récolter les zombies d'un parent
+func zombieRemediation(parent ProcessInfo) string {
+	if parent.PID == 1 {
+		return "PID 1 ne récolte pas ses enfants : vérifier le processus init (un init minimal comme tini évite ce cas)"
+	}
+	return fmt.Sprintf("kill -CHLD %d pour que '%s' récolte ses enfants ; s'ils persistent, "+
+		"terminer le parent (kill -TERM %d) : PID 1 les adoptera et les récoltera", parent.PID, parent.Name, parent.PID)
+}
@@ -0,0 +1,57 @@
+package procops
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiagnoseZombies(t *testing.T) {
+	procs, err := ProcFS{Root: fixtureRoot}.Processes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Le processus 300 (session 250 disparue) a démarré à boot+150s
+	now := fixtureBoot.Add(150*time.Second + 2*time.Hour)
+	report := diagnose(procs, time.Hour, now)
+
+	if report.Zombies != 1 || len(report.Groups) != 1 {
+		t.Fatalf("zombies = %d, groupes = %d, attendu 1 et 1", report.Zombies, len(report.Groups))
+	}
+	g := report.Groups[0]
+	if g.Parent.PID != 201 || g.Zombies[0].PID != 202 || g.Signal != "CHLD" {
+		t.Errorf("groupe inattendu : parent %d, zombies %v, signal %s", g.Parent.PID, pids(g.Zombies), g.Signal)
+	}
+
+	// sshd (chef de sa session) est un démon, pas un orphelin
+	if !report.OrphansChecked || len(report.Orphans) != 1 || report.Orphans[0].Process.PID != 300 {
+		t.Fatalf("orphelins = %+v, attendu le seul PID 300", report.Orphans)
+	}
+	if report.Orphans[0].Age != 2*time.Hour {
+		t.Errorf("âge = %s, attendu 2h", report.Orphans[0].Age)
+	}
+
+	if got := diagnose(procs, 3*time.Hour, now); len(got.Orphans) != 0 {
+		t.Errorf("orphelin trop récent signalé : %+v", got.Orphans)
+	}
+}
+
+func TestIsReaper(t *testing.T) {
+	tests := []struct {
+		p    ProcessInfo
+		want bool
+	}{
+		{ProcessInfo{PID: 1, Name: "init"}, true},
+		{ProcessInfo{PID: 900, Name: "systemd"}, true}, // systemd --user
+		{ProcessInfo{PID: 901, Name: "tini"}, true},
+		{ProcessInfo{PID: 902, Name: "containerd-shim"}, true},
+		{ProcessInfo{PID: 903, Name: "systemd-journald"}, false},
+		{ProcessInfo{PID: 904, Name: "systemd-resolved"}, false},
+		{ProcessInfo{PID: 905, Name: "systemd-udevd"}, false},
+		{ProcessInfo{PID: 906, Name: "tinyproxy"}, false},
+	}
+	for _, tt := range tests {
+		if got := isReaper(tt.p); got != tt.want {
+			t.Errorf("isReaper(%s) = %v, attendu %v", tt.p.Name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-devops-tool/procops"
+	"go-devops-tool/secureops"
+)
+
+// Délai maximal par défaut d'une commande lancée par proc run
+func runTimeout(cfg Config) time.Duration {
+	if cfg.RunTimeoutSec <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.RunTimeoutSec) * time.Second
+}
+
+// runMeasured exécute une commande avec délai maximal et journalise son coût.
+// La commande ayant son propre groupe de processus, Ctrl+C ne l'atteint pas
+// directement : il annule le contexte, ce qui tue tout le groupe. Pour la même
+// raison elle ne lit pas le terminal (entrée vide).
+func runMeasured(cfg Config, timeout time.Duration, stdout, stderr io.Writer, name string, args ...string) (procops.RunResult, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	line := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	opts := procops.RunOptions{Timeout: timeout, Stdout: stdout, Stderr: stderr}
+	result, err := procops.Run(ctx, opts, name, args...)
+	params := secureops.Params{"timeout_sec": timeout.Seconds()}
+	if err == nil {
+		params["pid"], params["exit_code"], params["timed_out"] = result.PID, result.ExitCode, result.TimedOut
+		params["wall_ms"], params["user_ms"], params["system_ms"] = result.Wall.Milliseconds(), result.User.Milliseconds(), result.System.Milliseconds()
+		params["max_rss_bytes"] = result.MaxRSS
+	}
+	outcome := err
+	if err == nil && result.TimedOut {
+		outcome = fmt.Errorf("délai de %s dépassé, groupe tué", timeout)
+	}
+	audit(cfg, "proc.run", line, params, outcome)
+	return result, err
+}
+
+// Résumé d'une exécution : issue, durée et ressources consommées
+func describeRun(r procops.RunResult) string {
+	outcome := fmt.Sprintf("code %d", r.ExitCode)
+	if r.TimedOut {
+		outcome = "délai dépassé, groupe tué"
+	}
+	return fmt.Sprintf("%s, durée %s, CPU user %s, sys %s, RSS max %s",
+		outcome, r.Wall.Round(time.Millisecond), r.User.Round(time.Millisecond),
+		r.System.Round(time.Millisecond), formatBytes(r.MaxRSS))
+}
+
+func printRunResult(r procops.RunResult) {
+	fmt.Printf("PID %d — %s\n", r.PID, describeRun(r))
+}
@@ -0,0 +1,135 @@
+package secureops
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Fichier du journal d'audit dans OutDir : une ligne JSON par action
+const auditFile = "audit.log"
+
+// Issue d'une action journalisée
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Params regroupe les paramètres d'une action (signal, raison, TTL...)
+type Params map[string]any
+
+// Actor identifie l'auteur d'une action
+type Actor struct {
+	UID      int    `json:"uid"` // -1 sous Windows
+	User     string `json:"user"`
+	Hostname string `json:"hostname"`
+	PID      int    `json:"pid"`
+}
+
+// AuditEntry est un enregistrement du journal d'audit
+type AuditEntry struct {
+	Time    time.Time `json:"time"`   // UTC
+	Action  string    `json:"action"` // type stable, ex: file.lock, proc.kill
+	Target  string    `json:"target,omitempty"`
+	Actor   Actor     `json:"actor"`
+	Params  Params    `json:"params,omitempty"`
+	Outcome string    `json:"outcome"` // success ou failure
+	Error   string    `json:"error,omitempty"`
+}
+
+// AuditError signale qu'une action n'a pas pu être journalisée. L'action
+// elle-même a pu réussir.
+type AuditError struct {
+	Err error
+}
+
+func (e *AuditError) Error() string {
+	return "écriture du journal d'audit impossible : " + e.Err.Error()
+}
+
+func (e *AuditError) Unwrap() error { return e.Err }
+
+var (
+	actorOnce sync.Once
+	actor     Actor
+	auditMu   sync.Mutex // une ligne entière à la fois entre goroutines
+)
+
+// currentActor décrit ce processus, déterminé une seule fois
+func currentActor() Actor {
+	actorOnce.Do(func() {
+		host, _ := os.Hostname()
+		actor = Actor{UID: os.Getuid(), User: currentUser(), Hostname: host, PID: os.Getpid()}
+	})
+	return actor
+}
+
+// LogAction enregistre une action dans le fichier audit.log. err est l'erreur
+// de l'action (nil = succès). Un échec d'écriture est retourné en *AuditError.
+func LogAction(outDir, action, target string, params Params, err error) error {
+	entry := AuditEntry{
+		Time:    time.Now().UTC(),
+		Action:  action,
+		Target:  target,
+		Actor:   currentActor(),
+		Params:  params,
+		Outcome: OutcomeSuccess,
+	}
+	if err != nil {
+		entry.Outcome, entry.Error = OutcomeFailure, err.Error()
+	}
+	if werr := appendAudit(outDir, entry); werr != nil {
+		return &AuditError{Err: werr}
+	}
+	return nil
+}
+
+// appendAudit ajoute l'enregistrement en une seule écriture
+func appendAudit(outDir string, entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(outDir, auditFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(line)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// MigrateAuditLog renomme un journal au format texte ([date] message) en
+// audit-legacy.log (ou audit-legacy-<date>.log s'il existe déjà), pour que
+// audit.log ne contienne que du JSON Lines. Seul le premier octet est lu.
+func MigrateAuditLog(outDir string) error {
+	path := filepath.Join(outDir, auditFile)
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	first := make([]byte, 1)
+	n, _ := f.Read(first)
+	f.Close()
+	if n == 0 || first[0] == '{' {
+		return nil
+	}
+
+	legacy := filepath.Join(outDir, "audit-legacy.log")
+	if _, err := os.Stat(legacy); err == nil {
+		legacy = filepath.Join(outDir, "audit-legacy-"+time.Now().Format("20060102-150405")+".log")
+	}
+	return os.Rename(path, legacy)
+}
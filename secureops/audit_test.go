@@ -0,0 +1,122 @@
+package secureops
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readAudit relit les enregistrements de audit.log
+func readAudit(t *testing.T, outDir string) []AuditEntry {
+	t.Helper()
+	f, err := os.Open(filepath.Join(outDir, auditFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("ligne non JSON %q : %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestLogActionJSONLines(t *testing.T) {
+	dir := t.TempDir()
+	if err := LogAction(dir, "proc.signal", "42", Params{"signal": "HUP"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := LogAction(dir, "proc.kill", "1", nil, errors.New("protégé")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readAudit(t, dir)
+	if len(entries) != 2 {
+		t.Fatalf("%d enregistrements, attendu 2", len(entries))
+	}
+	ok, failed := entries[0], entries[1]
+	if ok.Action != "proc.signal" || ok.Target != "42" || ok.Params["signal"] != "HUP" || ok.Outcome != OutcomeSuccess || ok.Error != "" {
+		t.Errorf("succès mal journalisé : %+v", ok)
+	}
+	if failed.Outcome != OutcomeFailure || failed.Error != "protégé" {
+		t.Errorf("échec mal journalisé : %+v", failed)
+	}
+	if ok.Actor.PID != os.Getpid() || ok.Actor.UID != os.Getuid() {
+		t.Errorf("acteur = %+v", ok.Actor)
+	}
+	if ok.Time.Location() != time.UTC || time.Since(ok.Time) > time.Minute {
+		t.Errorf("horodatage = %v", ok.Time)
+	}
+}
+
+func TestLogActionWriteError(t *testing.T) {
+	// Le dossier de sortie n'existe pas : l'écriture échoue
+	missing := filepath.Join(t.TempDir(), "absent")
+	err := LogAction(missing, "file.lock", "x", nil, nil)
+	var auditErr *AuditError
+	if !errors.As(err, &auditErr) {
+		t.Fatalf("AuditError attendue, obtenu %v", err)
+	}
+}
+
+func TestMigrateAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	old := "[2024-01-02 03:04:05] Verrouillage de : a.txt\n"
+	for round := 0; round < 2; round++ {
+		if err := os.WriteFile(filepath.Join(dir, auditFile), []byte(old), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := MigrateAuditLog(dir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, auditFile)); !os.IsNotExist(err) {
+			t.Errorf("audit.log toujours présent : %v", err)
+		}
+	}
+	// Le second ancien journal ne doit pas écraser le premier
+	legacy, _ := filepath.Glob(filepath.Join(dir, "audit-legacy*.log"))
+	if len(legacy) != 2 {
+		t.Fatalf("anciens journaux = %v", legacy)
+	}
+	for _, f := range legacy {
+		if data, _ := os.ReadFile(f); string(data) != old {
+			t.Errorf("%s = %q", f, data)
+		}
+	}
+
+	// Un journal déjà au format JSON reste en place
+	if err := LogAction(dir, "file.unlock", "a.txt", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateAuditLog(dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(readAudit(t, dir)) != 1 {
+		t.Error("journal JSON déplacé")
+	}
+}
+
+func TestLockFileAuditFailure(t *testing.T) {
+	dir := t.TempDir()
+	// audit.log est un dossier : le verrou est posé mais pas journalisé
+	if err := os.Mkdir(filepath.Join(dir, auditFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := LockFileWith("data.txt", dir, LockOptions{Persistent: true})
+	var auditErr *AuditError
+	if !errors.As(err, &auditErr) {
+		t.Fatalf("AuditError attendue, obtenu %v", err)
+	}
+	if !IsLocked("data.txt", dir) {
+		t.Error("le verrou doit être posé malgré l'échec du journal")
+	}
+}
@@ -0,0 +1,100 @@
+package secureops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLocked est retournée quand un verrou est déjà détenu
+var ErrLocked = errors.New("le fichier est déjà verrouillé")
+
+// Intervalle entre deux tentatives d'acquisition avec délai
+const lockPollInterval = 50 * time.Millisecond
+
+// LockMode est le type de verrou demandé
+type LockMode int
+
+const (
+	Exclusive LockMode = iota // un seul détenteur (écriture)
+	Shared                    // plusieurs lecteurs, exclut les verrous exclusifs
+)
+
+func (m LockMode) String() string {
+	if m == Shared {
+		return "partagé"
+	}
+	return "exclusif"
+}
+
+// Lock est un verrou consultatif détenu sur un fichier. Sous Unix il s'agit
+// d'un flock(2) sur le fichier lui-même, respecté par les autres programmes
+// qui utilisent flock (dont flock(1)) ; sous Windows, d'un marqueur
+// <fichier>.flock créé de façon atomique, où le mode partagé est dégradé en
+// exclusif (Mode retourne alors Exclusive).
+type Lock struct {
+	path   string
+	mode   LockMode
+	file   *os.File // descripteur portant le flock (Unix)
+	marker string   // marqueur créé à la place du flock (Windows)
+}
+
+// Path retourne le chemin du fichier verrouillé
+func (l *Lock) Path() string { return l.path }
+
+// Mode retourne le type de verrou détenu
+func (l *Lock) Mode() LockMode { return l.mode }
+
+// Release libère le verrou ; un second appel est sans effet
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	return l.release()
+}
+
+// Acquire attend que le verrou soit disponible puis le prend
+func Acquire(path string, mode LockMode) (*Lock, error) {
+	return acquire(path, mode, true)
+}
+
+// TryAcquire prend le verrou s'il est libre, sinon retourne ErrLocked
+func TryAcquire(path string, mode LockMode) (*Lock, error) {
+	return acquire(path, mode, false)
+}
+
+// AcquireTimeout réessaie jusqu'à timeout puis retourne ErrLocked
+func AcquireTimeout(path string, mode LockMode, timeout time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		l, err := TryAcquire(path, mode)
+		if !errors.Is(err, ErrLocked) {
+			return l, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s : %w (attente de %s)", path, ErrLocked, timeout)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// createExclusive crée un fichier marqueur de façon atomique (O_EXCL) :
+// parmi plusieurs appelants concurrents, un seul réussit
+func createExclusive(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrLocked
+		}
+		return err
+	}
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
@@ -0,0 +1,284 @@
+package secureops
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTryAcquireExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+
+	first, err := TryAcquire(path, Exclusive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TryAcquire(path, Exclusive); !errors.Is(err, ErrLocked) {
+		t.Fatalf("ErrLocked attendue, obtenu %v", err)
+	}
+	if _, err := TryAcquire(path, Shared); !errors.Is(err, ErrLocked) {
+		t.Fatalf("verrou partagé accordé malgré un verrou exclusif : %v", err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Release(); err != nil {
+		t.Errorf("second Release : %v", err)
+	}
+	second, err := TryAcquire(path, Exclusive)
+	if err != nil {
+		t.Fatalf("verrou non libéré : %v", err)
+	}
+	second.Release()
+}
+
+func TestAcquireTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	held, err := Acquire(path, Exclusive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := AcquireTimeout(path, Exclusive, 100*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("ErrLocked attendue, obtenu %v", err)
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("abandon avant la fin du délai")
+	}
+
+	time.AfterFunc(50*time.Millisecond, func() { held.Release() })
+	l, err := AcquireTimeout(path, Exclusive, 2*time.Second)
+	if err != nil {
+		t.Fatalf("verrou non obtenu après libération : %v", err)
+	}
+	l.Release()
+}
+
+func TestLockFileConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	const callers = 20
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	wins, locked := 0, 0
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := LockFile("data.txt", dir)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				wins++
+			case errors.Is(err, ErrLocked):
+				locked++
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if wins != 1 || locked != callers-1 {
+		t.Errorf("%d verrouillages réussis et %d refus, attendu 1 et %d", wins, locked, callers-1)
+	}
+	if !IsLocked("data.txt", dir) {
+		t.Error("IsLocked devrait être vrai")
+	}
+	if err := UnlockFile("data.txt", dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnlockFile("data.txt", dir); err == nil {
+		t.Error("erreur attendue pour un fichier non verrouillé")
+	}
+}
+
+func TestLockInfoStale(t *testing.T) {
+	now := time.Now()
+	self := newLockInfo("data.txt", LockOptions{})
+	if stale, _ := self.Stale(now); stale {
+		t.Error("verrou du processus courant jugé périmé")
+	}
+
+	expired := newLockInfo("data.txt", LockOptions{TTL: time.Minute, Persistent: true})
+	if stale, _ := expired.Stale(now.Add(2 * time.Minute)); !stale {
+		t.Error("TTL expiré non détecté")
+	}
+
+	dead := self
+	dead.PID = 1 << 30 // au-delà de tout PID possible
+	if stale, reason := dead.Stale(now); !stale || reason == "" {
+		t.Error("propriétaire disparu non détecté")
+	}
+	dead.Persistent = true
+	if stale, _ := dead.Stale(now); stale {
+		t.Error("un verrou persistant ne dépend pas de son propriétaire")
+	}
+
+	elsewhere := self
+	elsewhere.PID, elsewhere.Hostname = 1<<30, "autre-machine"
+	if stale, _ := elsewhere.Stale(now); stale {
+		t.Error("le PID d'une autre machine ne peut pas être vérifié")
+	}
+}
+
+// Variable d'environnement qui fait de l'exécutable de test un processus
+// qui pose un verrou puis se termine
+const lockHelperEnv = "SECUREOPS_LOCK_HELPER"
+
+func TestMain(m *testing.M) {
+	if dir := os.Getenv(lockHelperEnv); dir != "" {
+		opts := LockOptions{Persistent: os.Getenv(lockHelperEnv+"_PERSISTENT") != ""}
+		if err := LockFileWith(filepath.Join(dir, "data.txt"), dir, opts); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// lockFromExitedProcess pose le verrou depuis un processus déjà terminé
+func lockFromExitedProcess(t *testing.T, dir string, persistent bool) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), lockHelperEnv+"="+dir)
+	if persistent {
+		cmd.Env = append(cmd.Env, lockHelperEnv+"_PERSISTENT=1")
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("processus de verrouillage : %v\n%s", err, out)
+	}
+}
+
+func TestLockOwnerExited(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.txt")
+
+	// Verrou détenu (secure lock --hold) : périmé à la fin de son propriétaire
+	lockFromExitedProcess(t, dir, false)
+	if !IsLocked(path, dir) {
+		t.Fatal("marqueur absent")
+	}
+	if _, active := ActiveLock(path, dir); active {
+		t.Error("verrou d'un processus terminé jugé actif")
+	}
+	info, err := BreakStaleLock(path, dir)
+	if err != nil {
+		t.Fatalf("verrou d'un processus terminé non cassé : %v", err)
+	}
+	if info.Persistent || info.PID == os.Getpid() {
+		t.Errorf("verrou inattendu : %+v", info)
+	}
+
+	// Verrou persistant (secure lock) : survit à son propriétaire
+	lockFromExitedProcess(t, dir, true)
+	if _, active := ActiveLock(path, dir); !active {
+		t.Error("verrou persistant jugé périmé")
+	}
+	if _, err := BreakStaleLock(path, dir); !errors.Is(err, ErrLocked) {
+		t.Errorf("verrou persistant cassé : %v", err)
+	}
+}
+
+func TestBreakStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	if err := LockFileWith("data.txt", dir, LockOptions{TTL: time.Hour, Persistent: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BreakStaleLock("data.txt", dir); !errors.Is(err, ErrLocked) {
+		t.Fatalf("un verrou actif ne doit pas être cassé : %v", err)
+	}
+
+	info, err := ReadLock(lockPath("data.txt", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.AcquiredAt = info.AcquiredAt.Add(-2 * time.Hour)
+	if err := os.WriteFile(info.LockFile, info.encode(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BreakStaleLock("data.txt", dir); err != nil {
+		t.Fatalf("verrou expiré non cassé : %v", err)
+	}
+	if IsLocked("data.txt", dir) {
+		t.Error("le marqueur devrait être supprimé")
+	}
+
+	// Ancien format sans chemin : listé comme périmé, supprimé par son nom de base
+	legacy := filepath.Join(dir, "old.txt.lock")
+	os.WriteFile(legacy, []byte("LOCKED"), 0644)
+	locks, err := ListLocks(dir)
+	if err != nil || len(locks) != 1 || !locks[0].Legacy {
+		t.Fatalf("ListLocks = %+v, %v", locks, err)
+	}
+	if stale, reason := locks[0].Stale(time.Now()); !stale || reason == "" {
+		t.Error("un ancien verrou sans chemin doit être signalé")
+	}
+	if _, err := BreakStaleLock("old.txt", dir); err != nil {
+		t.Errorf("ancien verrou non supprimé : %v", err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("marqueur toujours présent : %v", err)
+	}
+}
+
+func TestLockSameBasename(t *testing.T) {
+	dir, out := t.TempDir(), t.TempDir()
+	a := filepath.Join(dir, "a", "config.txt")
+	b := filepath.Join(dir, "b", "config.txt")
+	for _, p := range []string{a, b} {
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte("x"), 0644)
+	}
+
+	if err := LockFile(a, out); err != nil {
+		t.Fatal(err)
+	}
+	if IsLocked(b, out) {
+		t.Fatal("verrouiller a/config.txt ne doit pas verrouiller b/config.txt")
+	}
+	if err := LockFile(b, out); err != nil {
+		t.Fatalf("b/config.txt : %v", err)
+	}
+
+	// Un lien symbolique désigne le même fichier que sa cible
+	link := filepath.Join(dir, "lien.txt")
+	if err := os.Symlink(a, link); err == nil && !IsLocked(link, out) {
+		t.Error("le lien vers a/config.txt devrait être verrouillé")
+	}
+}
+
+func TestMigrateLocks(t *testing.T) {
+	dir, out := t.TempDir(), t.TempDir()
+	a := filepath.Join(dir, "config.txt")
+
+	// Marqueur nommé d'après le nom de base, avec métadonnées : migré
+	info := newLockInfo(a, LockOptions{Persistent: true})
+	os.WriteFile(legacyLockPath(a, out), info.encode(), 0644)
+	// Ancien marqueur sans chemin : conservé mais ignoré
+	os.WriteFile(filepath.Join(out, "notes.txt.lock"), []byte("LOCKED"), 0644)
+
+	n, err := MigrateLocks(out)
+	if err != nil || n != 1 {
+		t.Fatalf("MigrateLocks = %d, %v ; attendu 1", n, err)
+	}
+	if _, err := os.Stat(lockPath(a, out)); err != nil {
+		t.Errorf("marqueur migré absent : %v", err)
+	}
+	if !IsLocked(a, out) {
+		t.Error("le fichier migré doit rester verrouillé")
+	}
+	other := filepath.Join(dir, "autre", "notes.txt")
+	if IsLocked(other, out) {
+		t.Error("un ancien marqueur sans chemin ne doit plus verrouiller les fichiers de ce nom")
+	}
+	if err := LockFile(other, out); err != nil {
+		t.Errorf("verrouillage bloqué par un ancien marqueur : %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !windows
+
+package secureops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// SharedLocks indique si la plateforme pose de vrais verrous partagés
+const SharedLocks = true
+
+// acquire ouvre le fichier (créé s'il n'existe pas) et pose un flock
+func acquire(path string, mode LockMode, wait bool) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	how := syscall.LOCK_EX
+	if mode == Shared {
+		how = syscall.LOCK_SH
+	}
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), how)
+		if !errors.Is(err, syscall.EINTR) {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%s : %w", path, ErrLocked)
+		}
+		return nil, err
+	}
+	return &Lock{path: path, mode: mode, file: f}, nil
+}
+
+// release lève le flock en fermant le descripteur
+func (l *Lock) release() error {
+	if l.file == nil {
+		return nil
+	}
+	err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file = nil
+	return err
+}
+
+// pidAlive teste l'existence d'un processus (signal 0)
+func pidAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,62 @@
+//go:build windows
+
+package secureops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Suffixe des marqueurs de Acquire, distinct de celui de secure lock (.lock)
+// pour qu'un fichier de OutDir puisse porter les deux sans collision
+const flockSuffix = ".flock"
+
+// SharedLocks indique si la plateforme pose de vrais verrous partagés : le
+// marqueur de Windows est toujours exclusif
+const SharedLocks = false
+
+// acquire crée le marqueur <fichier>.flock de façon atomique. Sans flock, le
+// mode partagé est dégradé en exclusif : Mode() du verrou retourne Exclusive.
+// Un marqueur laissé par un processus terminé est cassé automatiquement.
+func acquire(path string, mode LockMode, wait bool) (*Lock, error) {
+	mode = Exclusive
+	marker := path + flockSuffix
+	for {
+		err := createExclusive(marker, newLockInfo(path, LockOptions{}).encode())
+		if err == nil {
+			return &Lock{path: path, mode: mode, marker: marker}, nil
+		}
+		if !errors.Is(err, ErrLocked) {
+			return nil, err
+		}
+		if _, _, err := breakIfStale(marker); err == nil {
+			continue
+		}
+		if !wait {
+			return nil, fmt.Errorf("%s : %w", path, ErrLocked)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// release supprime le marqueur
+func (l *Lock) release() error {
+	if l.marker == "" {
+		return nil
+	}
+	err := os.Remove(l.marker)
+	l.marker = ""
+	return err
+}
+
+// pidAlive teste l'existence d'un processus
+func pidAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,225 @@
+package secureops
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Contenu des marqueurs créés avant l'ajout des métadonnées
+const legacyLockContent = "LOCKED"
+
+// Ces marqueurs ne portent que le nom de base : ils verrouillaient tous les
+// fichiers de ce nom, quel que soit leur dossier. Ils sont ignorés.
+const legacyStaleReason = "ancien verrou sans chemin, ignoré"
+
+// LockOptions décrit un verrouillage par marqueur
+type LockOptions struct {
+	Reason string
+	TTL    time.Duration // 0 = sans expiration
+	// Persistent : le verrou survit au processus qui l'a posé (secure lock).
+	// Sinon il est périmé dès que ce processus a disparu.
+	Persistent bool
+}
+
+// LockInfo est le contenu JSON d'un marqueur de verrou
+type LockInfo struct {
+	Path       string    `json:"path"` // fichier protégé (chemin absolu, liens résolus)
+	PID        int       `json:"pid"`
+	Hostname   string    `json:"hostname"`
+	User       string    `json:"user"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	Reason     string    `json:"reason,omitempty"`
+	TTLSec     int64     `json:"ttl_sec,omitempty"`
+	Persistent bool      `json:"persistent"`
+
+	LockFile string `json:"-"` // emplacement du marqueur
+	Legacy   bool   `json:"-"` // ancien marqueur "LOCKED" sans métadonnées
+}
+
+// newLockInfo décrit un verrou posé maintenant par ce processus
+func newLockInfo(path string, opts LockOptions) LockInfo {
+	host, _ := os.Hostname()
+	return LockInfo{
+		Path:       canonicalPath(path),
+		PID:        os.Getpid(),
+		Hostname:   host,
+		User:       currentUser(),
+		AcquiredAt: time.Now().UTC().Truncate(time.Second),
+		Reason:     opts.Reason,
+		TTLSec:     int64(opts.TTL / time.Second),
+		Persistent: opts.Persistent,
+	}
+}
+
+// currentUser retourne le nom de l'utilisateur courant
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// encode sérialise les métadonnées du marqueur
+func (info LockInfo) encode() []byte {
+	data, _ := json.MarshalIndent(info, "", "  ")
+	return append(data, '\n')
+}
+
+// Expires retourne la date d'expiration (zéro si le verrou n'expire pas)
+func (info LockInfo) Expires() time.Time {
+	if info.TTLSec <= 0 {
+		return time.Time{}
+	}
+	return info.AcquiredAt.Add(time.Duration(info.TTLSec) * time.Second)
+}
+
+// Stale indique si le verrou est périmé et pourquoi : TTL écoulé, ou
+// propriétaire disparu pour un verrou non persistant posé sur cette machine.
+// Un verrou d'une autre machine n'est jugé que sur son TTL. Un ancien
+// marqueur sans chemin est toujours périmé : il ne désigne aucun fichier précis.
+func (info LockInfo) Stale(now time.Time) (bool, string) {
+	if info.Legacy || info.Path == "" {
+		return true, legacyStaleReason
+	}
+	if exp := info.Expires(); !exp.IsZero() && now.After(exp) {
+		return true, "TTL expiré depuis " + exp.Local().Format("2006-01-02 15:04:05")
+	}
+	if info.Persistent || info.PID <= 0 {
+		return false, ""
+	}
+	if host, _ := os.Hostname(); info.Hostname != host {
+		return false, ""
+	}
+	if !pidAlive(info.PID) {
+		return true, fmt.Sprintf("processus propriétaire %d terminé", info.PID)
+	}
+	return false, ""
+}
+
+// parseLockInfo lit le contenu d'un marqueur ; "LOCKED" (ou un contenu
+// illisible) donne un verrou hérité, persistant
+func parseLockInfo(lockFile string, data []byte) LockInfo {
+	var info LockInfo
+	trimmed := bytes.TrimSpace(data)
+	if string(trimmed) == legacyLockContent || json.Unmarshal(trimmed, &info) != nil {
+		info = LockInfo{Legacy: true, Persistent: true}
+	}
+	info.LockFile = lockFile
+	return info
+}
+
+// ReadLock lit les métadonnées d'un marqueur de verrou
+func ReadLock(lockFile string) (LockInfo, error) {
+	data, err := os.ReadFile(lockFile)
+	if err != nil {
+		return LockInfo{}, err
+	}
+	info := parseLockInfo(lockFile, data)
+	if info.Legacy {
+		if st, err := os.Stat(lockFile); err == nil {
+			info.AcquiredAt = st.ModTime().UTC()
+		}
+	}
+	return info, nil
+}
+
+// ListLocks retourne les verrous posés dans outDir, les plus anciens d'abord
+func ListLocks(outDir string) ([]LockInfo, error) {
+	files, err := filepath.Glob(filepath.Join(outDir, "*.lock"))
+	if err != nil {
+		return nil, err
+	}
+	locks := []LockInfo{}
+	for _, f := range files {
+		info, err := ReadLock(f)
+		if err != nil {
+			// Supprimé entre la liste et la lecture
+			continue
+		}
+		locks = append(locks, info)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].AcquiredAt.Before(locks[j].AcquiredAt) })
+	return locks, nil
+}
+
+// breakIfStale supprime le marqueur s'il est périmé. Pour ne pas supprimer un
+// verrou posé entre-temps par un autre, le marqueur est d'abord renommé, puis
+// son contenu comparé à celui qui a été jugé périmé ; sinon il est remis en place.
+func breakIfStale(lockFile string) (LockInfo, string, error) {
+	data, err := os.ReadFile(lockFile)
+	if err != nil {
+		return LockInfo{}, "", err
+	}
+	info := parseLockInfo(lockFile, data)
+	stale, reason := info.Stale(time.Now())
+	if !stale {
+		return info, "", fmt.Errorf("verrou actif (%s) : %w", info.Owner(), ErrLocked)
+	}
+
+	tmp := lockFile + ".break." + strconv.Itoa(os.Getpid())
+	if err := os.Rename(lockFile, tmp); err != nil {
+		return info, "", err
+	}
+	moved, err := os.ReadFile(tmp)
+	if err != nil || !bytes.Equal(moved, data) {
+		// Un nouveau verrou a remplacé le marqueur périmé : on le restaure
+		// sans écraser un éventuel troisième verrou
+		if lerr := os.Link(tmp, lockFile); lerr == nil || errors.Is(lerr, os.ErrExist) {
+			os.Remove(tmp)
+		}
+		return info, "", fmt.Errorf("le verrou a changé pendant l'opération : %w", ErrLocked)
+	}
+	return info, reason, os.Remove(tmp)
+}
+
+// BreakStaleLock supprime le verrou de path dans outDir seulement s'il est
+// périmé, et journalise l'opération (*AuditError si seule la journalisation échoue)
+func BreakStaleLock(path, outDir string) (LockInfo, error) {
+	info, reason, err := breakStaleLock(path, outDir)
+	params := Params{}
+	if info.LockFile != "" {
+		params["owner"] = info.Owner()
+	}
+	if reason != "" {
+		params["stale"] = reason
+	}
+	if lerr := LogAction(outDir, "lock.break", canonicalPath(path), params, err); err == nil {
+		err = lerr
+	}
+	return info, err
+}
+
+func breakStaleLock(path, outDir string) (LockInfo, string, error) {
+	lockFile, ok := findLock(path, outDir)
+	if !ok {
+		// Ancien marqueur sans chemin, désigné par son nom de base
+		lockFile, ok = findPathlessLock(path, outDir)
+	}
+	if !ok {
+		return LockInfo{}, "", fmt.Errorf("le fichier n'est pas verrouillé")
+	}
+	info, reason, err := breakIfStale(lockFile)
+	if err != nil && os.IsNotExist(err) {
+		return info, "", fmt.Errorf("le fichier n'est pas verrouillé")
+	}
+	return info, reason, err
+}
+
+// Owner résume le propriétaire d'un verrou (utilisateur@hôte, PID)
+func (info LockInfo) Owner() string {
+	if info.Legacy {
+		return "inconnu (ancien verrou)"
+	}
+	return fmt.Sprintf("%s@%s, PID %d", info.User, info.Hostname, info.PID)
+}
@@ -0,0 +1,133 @@
+package secureops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// canonicalPath retourne le chemin absolu du fichier, liens symboliques
+// résolus. Pour un fichier qui n'existe pas encore, seul le répertoire est résolu.
+func canonicalPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		return filepath.Join(dir, filepath.Base(abs))
+	}
+	return abs
+}
+
+// lockPath retourne le marqueur de path dans outDir : <nom>.<empreinte>.lock,
+// l'empreinte portant sur le chemin canonique pour que a/config.txt et
+// b/config.txt aient des verrous distincts
+func lockPath(path, outDir string) string {
+	return lockPathFor(canonicalPath(path), outDir)
+}
+
+func lockPathFor(canonical, outDir string) string {
+	key := canonical
+	if runtime.GOOS == "windows" {
+		// Chemins insensibles à la casse
+		key = strings.ToLower(key)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(outDir, filepath.Base(canonical)+"."+hex.EncodeToString(sum[:6])+".lock")
+}
+
+// legacyLockPath retourne l'ancien marqueur, nommé d'après le seul nom de base
+func legacyLockPath(path, outDir string) string {
+	return filepath.Join(outDir, filepath.Base(path)+".lock")
+}
+
+// findLock retourne le marqueur qui verrouille path, s'il existe. Un marqueur
+// nommé d'après le nom de base compte s'il indique ce fichier ; les anciens
+// marqueurs sans chemin ne verrouillent plus rien (voir findPathlessLock).
+func findLock(path, outDir string) (string, bool) {
+	current := lockPath(path, outDir)
+	if _, err := os.Stat(current); err == nil {
+		return current, true
+	}
+	legacy := legacyLockPath(path, outDir)
+	info, err := ReadLock(legacy)
+	if err != nil {
+		return "", false
+	}
+	if info.Path != "" && info.Path == canonicalPath(path) {
+		return legacy, true
+	}
+	return "", false
+}
+
+// findPathlessLock retourne l'ancien marqueur sans chemin portant le nom de
+// base de path, pour pouvoir le supprimer
+func findPathlessLock(path, outDir string) (string, bool) {
+	legacy := legacyLockPath(path, outDir)
+	info, err := ReadLock(legacy)
+	if err != nil || info.Path != "" {
+		return "", false
+	}
+	return legacy, true
+}
+
+// MigrateLocks renomme les marqueurs nommés d'après le nom de base qui
+// indiquent le chemin protégé. Les marqueurs "LOCKED" sans chemin restent en
+// place, ignorés et signalés comme périmés par ListLocks.
+func MigrateLocks(outDir string) (int, error) {
+	locks, err := ListLocks(outDir)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, l := range locks {
+		if l.Legacy || l.Path == "" {
+			continue
+		}
+		target := lockPathFor(l.Path, outDir)
+		if l.LockFile == target {
+			continue
+		}
+		// Link échoue si un verrou existe déjà sous le nouveau nom : l'ancien est conservé
+		if err := os.Link(l.LockFile, target); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return migrated, err
+		}
+		if err := os.Remove(l.LockFile); err != nil {
+			return migrated, err
+		}
+		migrated++
+		params := Params{"from": filepath.Base(l.LockFile), "to": filepath.Base(target)}
+		if err := LogAction(outDir, "lock.migrate", l.Path, params, nil); err != nil {
+			return migrated, err
+		}
+	}
+	return migrated, nil
+}
+
+// ActiveLock retourne le verrou qui protège path s'il est encore valide.
+// Un verrou périmé (TTL écoulé, propriétaire disparu) n'est pas retourné :
+// il ne bloque plus les écritures, sans être supprimé pour autant.
+func ActiveLock(path, outDir string) (LockInfo, bool) {
+	lockFile, ok := findLock(path, outDir)
+	if !ok {
+		return LockInfo{}, false
+	}
+	info, err := ReadLock(lockFile)
+	if err != nil {
+		return LockInfo{}, false
+	}
+	if stale, _ := info.Stale(time.Now()); stale {
+		return info, false
+	}
+	return info, true
+}
@@ -1,63 +1,87 @@
 package secureops
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"time"
 )
 
-// LogAction enregistre une action dans le fichier audit.log
-func LogAction(outDir, action string) error {
-	logFile := filepath.Join(outDir, "audit.log")
-	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+// LockFile crée un fichier de verrouillage (.lock) persistant. La création
+// est atomique : si deux appels sont concurrents, l'un reçoit ErrLocked.
+// Pour un verrou respecté par les autres programmes, voir Acquire.
+func LockFile(path, outDir string) error {
+	return LockFileWith(path, outDir, LockOptions{Persistent: true})
+}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	_, err = f.WriteString(fmt.Sprintf("[%s] %s\n", timestamp, action))
+// LockFileWith crée le fichier de verrouillage avec ses métadonnées (JSON) :
+// propriétaire, date, raison et durée de validité. Succès et refus sont
+// journalisés ; un *AuditError signale un verrou posé mais non journalisé.
+func LockFileWith(path, outDir string, opts LockOptions) error {
+	params := Params{"persistent": opts.Persistent}
+	if opts.Reason != "" {
+		params["reason"] = opts.Reason
+	}
+	if opts.TTL > 0 {
+		params["ttl_sec"] = int64(opts.TTL / time.Second)
+	}
+	err := createLock(path, outDir, opts)
+	if lerr := LogAction(outDir, "file.lock", canonicalPath(path), params, err); err == nil {
+		err = lerr
+	}
 	return err
 }
 
-// LockFile crée un fichier de verrouillage (.lock)
-func LockFile(path, outDir string) error {
-	lockFile := filepath.Join(outDir, filepath.Base(path)+".lock")
-	if _, err := os.Stat(lockFile); err == nil {
-		return fmt.Errorf("le fichier est déjà verrouillé")
+// createLock crée le marqueur s'il n'existe ni sous son nom ni sous l'ancien
+func createLock(path, outDir string, opts LockOptions) error {
+	if existing, ok := findLock(path, outDir); ok {
+		if info, err := ReadLock(existing); err == nil {
+			return fmt.Errorf("%w (%s)", ErrLocked, info.Owner())
+		}
+		return ErrLocked
 	}
-
-	err := os.WriteFile(lockFile, []byte("LOCKED"), 0644)
-	if err != nil {
+	lockFile := lockPath(path, outDir)
+	if err := createExclusive(lockFile, newLockInfo(path, opts).encode()); err != nil {
+		if errors.Is(err, ErrLocked) {
+			if info, rerr := ReadLock(lockFile); rerr == nil {
+				return fmt.Errorf("%w (%s)", ErrLocked, info.Owner())
+			}
+		}
 		return err
 	}
-
-	return LogAction(outDir, "Verrouillage de : "+path)
+	return nil
 }
 
-// UnlockFile supprime le fichier de verrouillage
+// UnlockFile supprime le fichier de verrouillage ; comme pour LockFileWith, un
+// *AuditError signale une suppression effectuée mais non journalisée
 func UnlockFile(path, outDir string) error {
-	lockFile := filepath.Join(outDir, filepath.Base(path)+".lock")
-	if _, err := os.Stat(lockFile); os.IsNotExist(err) {
-		return fmt.Errorf("le fichier n'est pas verrouillé")
+	err := removeLock(path, outDir)
+	if lerr := LogAction(outDir, "file.unlock", canonicalPath(path), nil, err); err == nil {
+		err = lerr
 	}
+	return err
+}
 
-	err := os.Remove(lockFile)
-	if err != nil {
+func removeLock(path, outDir string) error {
+	lockFile, ok := findLock(path, outDir)
+	if !ok {
+		return fmt.Errorf("le fichier n'est pas verrouillé")
+	}
+	if err := os.Remove(lockFile); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("le fichier n'est pas verrouillé")
+		}
 		return err
 	}
-
-	return LogAction(outDir, "Déverrouillage de : "+path)
+	return nil
 }
 
 // IsLocked vérifie si un fichier est verrouillé
 func IsLocked(path, outDir string) bool {
-	lockFile := filepath.Join(outDir, filepath.Base(path)+".lock")
-	_, err := os.Stat(lockFile)
-	return err == nil
+	_, ok := findLock(path, outDir)
+	return ok
 }
 
 // SetReadOnly tente de rendre un fichier en lecture seule selon l'OS
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"go-devops-tool/procops"
+	"go-devops-tool/secureops"
+)
+
+// newSupervisor prépare les services de la config ; leurs sorties sont
+// capturées dans OutDir/services et chaque événement est journalisé, et
+// affiché sur echo s'il n'est pas nil
+func newSupervisor(cfg Config, echo io.Writer) (*procops.Supervisor, error) {
+	sup, err := procops.NewSupervisor(cfg.Services, filepath.Join(cfg.OutDir, "services"), killGrace(cfg))
+	if err != nil {
+		return nil, err
+	}
+	sup.OnEvent = func(name, message string) {
+		audit(cfg, "service.event", name, secureops.Params{"event": message}, nil)
+		if echo != nil {
+			fmt.Fprintf(echo, "[%s] Service %s : %s\n", time.Now().Format("15:04:05"), name, message)
+		}
+	}
+	return sup, nil
+}
+
+// serviceSummary est le bilan d'un service en fin de supervision
+type serviceSummary struct {
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	LastPID   int    `json:"last_pid,omitempty"` // PID au moment de l'arrêt
+	Restarts  int    `json:"restarts"`
+	ExitCode  int    `json:"last_exit_code"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// runSupervisorForeground démarre les services demandés (tous si names est
+// vide) et les supervise jusqu'à SIGINT ou SIGTERM. La progression est
+// écrite sur progress ; le bilan des services est retourné après l'arrêt.
+func runSupervisorForeground(cfg Config, names []string, progress io.Writer) ([]serviceSummary, error) {
+	sup, err := newSupervisor(cfg, progress)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		names = sup.Names()
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("aucun service défini dans la config")
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	for _, name := range names {
+		if err := sup.Start(name); err != nil {
+			sup.StopAll()
+			return nil, err
+		}
+	}
+	fmt.Fprintln(progress, "Supervision en cours, Ctrl+C pour arrêter.")
+	<-sigs
+	fmt.Fprintln(progress, "Arrêt des services...")
+
+	// L'arrêt remet les PID à zéro : on garde ceux relevés juste avant
+	lastPID := map[string]int{}
+	for _, st := range sup.Status() {
+		lastPID[st.Name] = st.PID
+	}
+	sup.StopAll()
+
+	summary := []serviceSummary{}
+	for _, st := range sup.Status() {
+		summary = append(summary, serviceSummary{Name: st.Name, State: st.State, LastPID: lastPID[st.Name],
+			Restarts: st.Restarts, ExitCode: st.ExitCode, LastError: st.LastError})
+	}
+	return summary, nil
+}
+
+// Affiche l'état des services supervisés
+func printServices(statuses []procops.ServiceStatus) {
+	if len(statuses) == 0 {
+		fmt.Println("Aucun service défini dans la config.")
+		return
+	}
+	fmt.Printf("%-15s | %-8s | %-7s | %-9s | %-4s | %-20s\n", "SERVICE", "ÉTAT", "PID", "REDÉMARR.", "CODE", "DÉMARRÉ")
+	fmt.Println("-------------------------------------------------------------------------------")
+	for _, st := range statuses {
+		pid, started := "-", "-"
+		if st.PID > 0 {
+			pid = fmt.Sprint(st.PID)
+		}
+		if !st.StartedAt.IsZero() {
+			started = st.StartedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-15s | %-8s | %-7s | %-9d | %-4d | %-20s\n", st.Name, st.State, pid, st.Restarts, st.ExitCode, started)
+		if st.LastError != "" {
+			fmt.Println("  Erreur :", st.LastError)
+		}
+	}
+}
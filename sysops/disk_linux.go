@@ -0,0 +1,89 @@
+//go:build linux
+
+package sysops
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Systèmes de fichiers virtuels ignorés dans la vue disque
+var pseudoFS = map[string]bool{
+	"proc": true, "sysfs": true, "devpts": true, "devtmpfs": true,
+	"cgroup": true, "cgroup2": true, "securityfs": true, "debugfs": true,
+	"tracefs": true, "mqueue": true, "pstore": true, "bpf": true,
+	"configfs": true, "fusectl": true, "hugetlbfs": true, "autofs": true,
+	"binfmt_misc": true, "nsfs": true, "rpc_pipefs": true, "efivarfs": true,
+	"selinuxfs": true,
+}
+
+// Disks lit les points de montage de /proc/mounts et leur occupation (statfs)
+func Disks() ([]DiskUsage, error) {
+	f, err := os.Open(filepath.Join(procRoot, "mounts"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seen := map[string]bool{}
+	disks := []DiskUsage{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Format : périphérique point_de_montage type options 0 0
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || pseudoFS[fields[2]] {
+			continue
+		}
+		mount := unescapeMount(fields[1])
+		if seen[mount] {
+			continue
+		}
+
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(mount, &st); err != nil || st.Blocks == 0 {
+			// Montage inaccessible ou sans capacité (ex: tmpfs vide de taille nulle)
+			continue
+		}
+		seen[mount] = true
+
+		bsize := uint64(st.Bsize)
+		d := DiskUsage{
+			Mount:     mount,
+			Device:    fields[0],
+			FSType:    fields[2],
+			Total:     st.Blocks * bsize,
+			Free:      st.Bfree * bsize,
+			Available: st.Bavail * bsize,
+		}
+		d.Used = d.Total - d.Free
+		// Même calcul que df : utilisé / (utilisé + disponible)
+		if d.Used+d.Available > 0 {
+			d.UsedPercent = float64(d.Used) / float64(d.Used+d.Available) * 100
+		}
+		disks = append(disks, d)
+	}
+	return disks, scanner.Err()
+}
+
+// unescapeMount décode les séquences octales de /proc/mounts (\040 = espace)
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package sysops
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Disks n'est implémenté que sous Linux (/proc/mounts)
+func Disks() ([]DiskUsage, error) {
+	return nil, fmt.Errorf("vue disque non supportée sur %s", runtime.GOOS)
+}
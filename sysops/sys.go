@@ -0,0 +1,263 @@
+package sysops
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Racine du pseudo-système de fichiers lu pour la vue système (Linux)
+const procRoot = "/proc"
+
+// Overview est le résumé de l'état du système
+type Overview struct {
+	Hostname    string        `json:"hostname"`
+	CollectedAt time.Time     `json:"collected_at"`
+	Uptime      time.Duration `json:"uptime_ns"`
+	Load        LoadAvg       `json:"load"`
+	Memory      MemInfo       `json:"memory"`
+	CPU         CPUUsage      `json:"cpu"`
+	Disks       []DiskUsage   `json:"disks"`
+}
+
+// LoadAvg correspond à /proc/loadavg
+type LoadAvg struct {
+	Load1   float64 `json:"load1"`
+	Load5   float64 `json:"load5"`
+	Load15  float64 `json:"load15"`
+	Running int     `json:"running"` // entités ordonnançables en cours d'exécution
+	Total   int     `json:"total"`   // entités ordonnançables existantes
+}
+
+// MemInfo reprend les champs utiles de /proc/meminfo, en octets
+type MemInfo struct {
+	Total     uint64 `json:"total_bytes"`
+	Free      uint64 `json:"free_bytes"`
+	Available uint64 `json:"available_bytes"`
+	Buffers   uint64 `json:"buffers_bytes"`
+	Cached    uint64 `json:"cached_bytes"`
+	SwapTotal uint64 `json:"swap_total_bytes"`
+	SwapFree  uint64 `json:"swap_free_bytes"`
+}
+
+// Used retourne la mémoire réellement utilisée (hors cache récupérable)
+func (m MemInfo) Used() uint64 {
+	if m.Available > m.Total {
+		return 0
+	}
+	return m.Total - m.Available
+}
+
+// CPUTimes est la ligne "cpu" agrégée de /proc/stat, en tops d'horloge
+type CPUTimes struct {
+	User, Nice, System, Idle, IOWait, IRQ, SoftIRQ, Steal uint64
+}
+
+// Total retourne la somme de tous les temps
+func (t CPUTimes) Total() uint64 {
+	return t.User + t.Nice + t.System + t.Idle + t.IOWait + t.IRQ + t.SoftIRQ + t.Steal
+}
+
+// Busy retourne le temps passé hors inactivité
+func (t CPUTimes) Busy() uint64 {
+	return t.Total() - t.Idle - t.IOWait
+}
+
+// CPUUsage résume l'activité des processeurs
+type CPUUsage struct {
+	Cores        int           `json:"cores"`
+	UsagePercent float64       `json:"usage_percent"` // tous cœurs confondus, 0-100
+	Window       time.Duration `json:"window_ns"`     // période de mesure (0 = depuis le démarrage)
+}
+
+// DiskUsage décrit l'occupation d'un système de fichiers monté
+type DiskUsage struct {
+	Mount       string  `json:"mount"`
+	Device      string  `json:"device"`
+	FSType      string  `json:"fs_type"`
+	Total       uint64  `json:"total_bytes"`
+	Free        uint64  `json:"free_bytes"`
+	Available   uint64  `json:"available_bytes"` // disponible pour un utilisateur non root
+	Used        uint64  `json:"used_bytes"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// Collect rassemble toutes les informations. L'utilisation CPU est mesurée
+// entre deux lectures de /proc/stat espacées de sample (depuis le démarrage si 0).
+func Collect(sample time.Duration) (Overview, error) {
+	var o Overview
+	var err error
+
+	o.Hostname, _ = os.Hostname()
+	if o.Load, err = LoadAverage(); err != nil {
+		return o, err
+	}
+	if o.Memory, err = Memory(); err != nil {
+		return o, err
+	}
+	if o.Uptime, err = Uptime(); err != nil {
+		return o, err
+	}
+	if o.CPU, err = CPUUsageOver(sample); err != nil {
+		return o, err
+	}
+	if o.Disks, err = Disks(); err != nil {
+		return o, err
+	}
+	o.CollectedAt = time.Now()
+	return o, nil
+}
+
+// LoadAverage lit /proc/loadavg
+func LoadAverage() (LoadAvg, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "loadavg"))
+	if err != nil {
+		return LoadAvg{}, unsupported(err)
+	}
+	return parseLoadAvg(data)
+}
+
+// parseLoadAvg analyse le contenu de /proc/loadavg
+func parseLoadAvg(data []byte) (LoadAvg, error) {
+	// Format : "0.12 0.34 0.56 2/345 6789"
+	fields := strings.Fields(string(data))
+	if len(fields) < 4 {
+		return LoadAvg{}, fmt.Errorf("format loadavg invalide")
+	}
+	var l LoadAvg
+	l.Load1, _ = strconv.ParseFloat(fields[0], 64)
+	l.Load5, _ = strconv.ParseFloat(fields[1], 64)
+	l.Load15, _ = strconv.ParseFloat(fields[2], 64)
+	if running, total, ok := strings.Cut(fields[3], "/"); ok {
+		l.Running, _ = strconv.Atoi(running)
+		l.Total, _ = strconv.Atoi(total)
+	}
+	return l, nil
+}
+
+// Memory lit /proc/meminfo (valeurs en kB converties en octets)
+func Memory() (MemInfo, error) {
+	f, err := os.Open(filepath.Join(procRoot, "meminfo"))
+	if err != nil {
+		return MemInfo{}, unsupported(err)
+	}
+	defer f.Close()
+	return parseMemInfo(f)
+}
+
+// parseMemInfo analyse le contenu de /proc/meminfo
+func parseMemInfo(r io.Reader) (MemInfo, error) {
+	fields := map[string]*uint64{}
+	var m MemInfo
+	fields["MemTotal"] = &m.Total
+	fields["MemFree"] = &m.Free
+	fields["MemAvailable"] = &m.Available
+	fields["Buffers"] = &m.Buffers
+	fields["Cached"] = &m.Cached
+	fields["SwapTotal"] = &m.SwapTotal
+	fields["SwapFree"] = &m.SwapFree
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		if dst, ok := fields[key]; ok {
+			kb, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+			*dst = kb * 1024
+		}
+	}
+	return m, scanner.Err()
+}
+
+// Uptime lit /proc/uptime
+func Uptime() (time.Duration, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "uptime"))
+	if err != nil {
+		return 0, unsupported(err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("format uptime invalide")
+	}
+	sec, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(sec * float64(time.Second)), nil
+}
+
+// ReadCPUTimes lit la ligne "cpu" de /proc/stat
+func ReadCPUTimes() (CPUTimes, error) {
+	f, err := os.Open(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return CPUTimes{}, unsupported(err)
+	}
+	defer f.Close()
+	return parseCPUTimes(f)
+}
+
+// parseCPUTimes cherche la ligne "cpu" agrégée dans le contenu de /proc/stat
+func parseCPUTimes(r io.Reader) (CPUTimes, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || fields[0] != "cpu" {
+			continue
+		}
+		v := make([]uint64, 8)
+		for i := range v {
+			v[i], _ = strconv.ParseUint(fields[i+1], 10, 64)
+		}
+		return CPUTimes{User: v[0], Nice: v[1], System: v[2], Idle: v[3],
+			IOWait: v[4], IRQ: v[5], SoftIRQ: v[6], Steal: v[7]}, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return CPUTimes{}, err
+	}
+	return CPUTimes{}, fmt.Errorf("ligne cpu absente de %s/stat", procRoot)
+}
+
+// busyPercent retourne la part du temps CPU passée hors inactivité entre deux
+// relevés. Un compteur qui recule (relevé remis à zéro) ne donne pas d'écart négatif.
+func busyPercent(before, after CPUTimes) float64 {
+	if after.Total() <= before.Total() || after.Busy() < before.Busy() {
+		return 0
+	}
+	return float64(after.Busy()-before.Busy()) / float64(after.Total()-before.Total()) * 100
+}
+
+// CPUUsageOver mesure l'utilisation CPU sur la période donnée
+func CPUUsageOver(sample time.Duration) (CPUUsage, error) {
+	usage := CPUUsage{Cores: runtime.NumCPU(), Window: sample}
+	before, err := ReadCPUTimes()
+	if err != nil {
+		return usage, err
+	}
+	after := before
+	if sample > 0 {
+		time.Sleep(sample)
+		if after, err = ReadCPUTimes(); err != nil {
+			return usage, err
+		}
+		usage.UsagePercent = busyPercent(before, after)
+		return usage, nil
+	}
+	usage.UsagePercent = busyPercent(CPUTimes{}, after)
+	return usage, nil
+}
+
+// unsupported précise que /proc n'existe que sous Linux
+func unsupported(err error) error {
+	if os.IsNotExist(err) && runtime.GOOS != "linux" {
+		return fmt.Errorf("vue système non supportée sur %s : %w", runtime.GOOS, err)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package sysops
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLoadAvg(t *testing.T) {
+	l, err := parseLoadAvg([]byte("0.12 0.34 1.56 2/345 6789\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (LoadAvg{Load1: 0.12, Load5: 0.34, Load15: 1.56, Running: 2, Total: 345}); l != want {
+		t.Errorf("loadavg = %+v, attendu %+v", l, want)
+	}
+	if _, err := parseLoadAvg([]byte("0.12 0.34\n")); err == nil {
+		t.Error("erreur attendue pour un contenu tronqué")
+	}
+}
+
+func TestParseMemInfo(t *testing.T) {
+	const meminfo = `MemTotal:       16303428 kB
+MemFree:         1234567 kB
+MemAvailable:    8151714 kB
+Buffers:          204800 kB
+Cached:          4096000 kB
+SwapCached:        10240 kB
+Active(anon):    2048000 kB
+SwapTotal:       2097148 kB
+SwapFree:        2097148 kB
+HugePages_Total:       0
+`
+	m, err := parseMemInfo(strings.NewReader(meminfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MemInfo{
+		Total:     16303428 * 1024,
+		Free:      1234567 * 1024,
+		Available: 8151714 * 1024,
+		Buffers:   204800 * 1024,
+		Cached:    4096000 * 1024, // SwapCached ne doit pas l'écraser
+		SwapTotal: 2097148 * 1024,
+		SwapFree:  2097148 * 1024,
+	}
+	if m != want {
+		t.Errorf("meminfo = %+v, attendu %+v", m, want)
+	}
+	if used := m.Used(); used != (16303428-8151714)*1024 {
+		t.Errorf("Used = %d", used)
+	}
+	if used := (MemInfo{Total: 1, Available: 2}).Used(); used != 0 {
+		t.Errorf("Used avec Available > Total = %d", used)
+	}
+}
+
+func TestParseCPUTimes(t *testing.T) {
+	tests := []struct {
+		name, stat string
+		want       CPUTimes
+		ok         bool
+	}{
+		{
+			"agrégée avant les cœurs",
+			"cpu  100 5 50 800 20 3 2 1 0 0\ncpu0 50 2 25 400 10 1 1 0 0 0\nintr 12345\n",
+			CPUTimes{User: 100, Nice: 5, System: 50, Idle: 800, IOWait: 20, IRQ: 3, SoftIRQ: 2, Steal: 1},
+			true,
+		},
+		{
+			"cœurs seuls ignorés",
+			"cpu0 50 2 25 400 10 1 1 0 0 0\nctxt 42\n",
+			CPUTimes{},
+			false,
+		},
+		{
+			"ligne cpu tronquée",
+			"cpu  100 5 50\n",
+			CPUTimes{},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseCPUTimes(strings.NewReader(tt.stat))
+		if (err == nil) != tt.ok {
+			t.Errorf("%s : erreur = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s : %+v, attendu %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBusyPercent(t *testing.T) {
+	before := CPUTimes{User: 100, System: 50, Idle: 800, IOWait: 50}
+	tests := []struct {
+		name  string
+		after CPUTimes
+		want  float64
+	}{
+		{"moitié occupé", CPUTimes{User: 150, System: 100, Idle: 900, IOWait: 50}, 50},
+		{"inactif", CPUTimes{User: 100, System: 50, Idle: 900, IOWait: 50}, 0},
+		{"aucun écart", before, 0},
+		{"compteur en recul", CPUTimes{User: 90, System: 50, Idle: 1000, IOWait: 50}, 0},
+	}
+	for _, tt := range tests {
+		if got := busyPercent(before, tt.after); got != tt.want {
+			t.Errorf("%s : busyPercent = %v, attendu %v", tt.name, got, tt.want)
+		}
+	}
+	if got := busyPercent(CPUTimes{}, before); got != 15 {
+		t.Errorf("depuis le démarrage = %v, attendu 15", got)
+	}
+}
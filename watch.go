@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"go-devops-tool/procops"
+)
+
+// Intervalle de rafraîchissement par défaut de la vue temps réel
+func processRefresh(cfg Config) time.Duration {
+	if cfg.ProcessRefreshSec <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(cfg.ProcessRefreshSec) * time.Second
+}
+
+// rawTerminal passe le terminal en mode caractère (sans Entrée ni écho) via
+// stty et retourne la fonction de restauration. Sous Windows ou hors terminal,
+// rien n'est modifié et il faudra valider q par Entrée.
+func rawTerminal() (restore func(), ok bool) {
+	if runtime.GOOS == "windows" {
+		return func() {}, false
+	}
+	save := exec.Command("stty", "-g")
+	save.Stdin = os.Stdin
+	state, err := save.Output()
+	if err != nil {
+		return func() {}, false
+	}
+	raw := exec.Command("stty", "-icanon", "-echo", "min", "1")
+	raw.Stdin = os.Stdin
+	if err := raw.Run(); err != nil {
+		return func() {}, false
+	}
+	return func() {
+		cmd := exec.Command("stty", strings.TrimSpace(string(state)))
+		cmd.Stdin = os.Stdin
+		cmd.Run()
+	}, true
+}
+
+// watchQuit lit l'entrée standard jusqu'à la touche q (ou la fin de l'entrée).
+// En mode ligne, le reste de la ligne est consommé pour ne pas perturber le menu.
+// La lecture ne peut pas être interrompue : l'appelant attend la fermeture du
+// canal avant de rendre la main, sinon la saisie suivante du menu serait perdue.
+func watchQuit(reader *bufio.Reader, raw bool) <-chan struct{} {
+	quit := make(chan struct{})
+	go func() {
+		defer close(quit)
+		for {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			if b == 'q' || b == 'Q' {
+				if !raw {
+					reader.ReadString('\n')
+				}
+				return
+			}
+		}
+	}()
+	return quit
+}
+
+// runProcWatch affiche les processus triés et rafraîchis à chaque intervalle
+// jusqu'à ce que l'utilisateur appuie sur q
+func runProcWatch(reader *bufio.Reader, opts procops.ListOptions, interval time.Duration) error {
+	restore, raw := rawTerminal()
+	defer restore()
+
+	// Ctrl+C : on remet le terminal en état avant de quitter
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	defer signal.Stop(sigs)
+
+	hint := "q pour quitter"
+	if !raw {
+		hint = "q puis Entrée pour quitter"
+	}
+
+	// Premier relevé avant de lire l'entrée : une erreur immédiate rend la
+	// main au menu sans laisser de lecture en cours
+	sampler := procops.NewSampler()
+	procs, err := sampler.Sample(opts)
+	if err != nil {
+		return err
+	}
+	quit := watchQuit(reader, raw)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("Processus — %s — tri %s — rafraîchi toutes les %s — %s\n\n",
+			time.Now().Format("15:04:05"), opts.SortBy, interval, hint)
+		printWatchTable(procs)
+
+		select {
+		case <-quit:
+			return nil
+		case <-sigs:
+			restore()
+			fmt.Println()
+			os.Exit(130)
+		case <-ticker.C:
+		}
+
+		procs, err = sampler.Sample(opts)
+		if err != nil {
+			// watchQuit lit encore l'entrée : on attend q pour la rendre au menu
+			fmt.Println("Relevé impossible —", hint)
+			waitQuit(quit, sigs, restore)
+			return err
+		}
+	}
+}
+
+// waitQuit attend la fin de watchQuit (touche q) ; Ctrl+C quitte l'outil
+func waitQuit(quit <-chan struct{}, sigs <-chan os.Signal, restore func()) {
+	select {
+	case <-quit:
+	case <-sigs:
+		restore()
+		fmt.Println()
+		os.Exit(130)
+	}
+}
+
+// Tableau de la vue temps réel (avec %CPU)
+func printWatchTable(procs []procops.ProcessInfo) {
+	fmt.Printf("%-7s | %-10s | %-1s | %6s | %9s | %10s | %-30s\n", "PID", "USER", "S", "%CPU", "RSS", "CPU", "NOM")
+	fmt.Println("-------------------------------------------------------------------------------------")
+	for _, p := range procs {
+		fmt.Printf("%-7d | %-10.10s | %-1s | %6.1f | %9s | %10s | %-30s\n",
+			p.PID, p.User, p.State, p.CPUPercent, formatBytes(p.RSS), formatDuration(p.CPUTime), p.Name)
+	}
+}
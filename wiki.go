@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery" // WebOps
+)
+
+// fetchWikiArticle récupère le texte des paragraphes d'un article Wikipédia
+func fetchWikiArticle(article string) (string, error) {
+	url := "https://fr.wikipedia.org/wiki/" + article
+	client := &http.Client{}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) "+
+		"AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Safari/537.36")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("page non trouvée, code : %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("erreur parsing : %w", err)
+	}
+
+	text := ""
+	doc.Find("div#mw-content-text div.mw-parser-output p").Each(func(i int, s *goquery.Selection) {
+		p := strings.TrimSpace(s.Text())
+		if p != "" {
+			text += p + "\n"
+		}
+	})
+
+	if text == "" {
+		return "", fmt.Errorf("aucun texte trouvé dans l'article")
+	}
+	return text, nil
+}
+
+// textWordStats : nombre de mots (ignore les nombres) et longueur moyenne
+func textWordStats(text string) (int, float64) {
+	words := strings.Fields(text)
+	totalWords, totalLength := 0, 0
+	for _, w := range words {
+		if _, err := strconv.Atoi(w); err == nil {
+			continue
+		}
+		totalWords++
+		totalLength += len(w)
+	}
+	avgLength := 0.0
+	if totalWords > 0 {
+		avgLength = float64(totalLength) / float64(totalWords)
+	}
+	return totalWords, avgLength
+}